@@ -0,0 +1,246 @@
+package mappo
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// BitSet is a set of non-negative integers backed by a []uint64 of words, one
+// bit per possible element. For dense IDs it uses a small fraction of the
+// memory of Set[int], and the set operations work a word at a time.
+// Memory grows with the largest element, so it is a poor fit for sparse IDs.
+// The zero value is an empty set. BitSet is not safe for concurrent use.
+type BitSet struct {
+	words []uint64
+}
+
+// NewBitSet creates a BitSet holding elems. Panics if any is negative.
+func NewBitSet(elems ...int) *BitSet {
+	s := &BitSet{}
+	for _, elem := range elems {
+		s.Add(elem)
+	}
+	return s
+}
+
+// Add adds an element to the set. Panics if elem is negative.
+func (s *BitSet) Add(elem int) {
+	if elem < 0 {
+		panic("mappo: negative BitSet element")
+	}
+	w := elem >> 6
+	if w >= len(s.words) {
+		s.words = append(s.words, make([]uint64, w+1-len(s.words))...)
+	}
+	s.words[w] |= 1 << (uint(elem) & 63)
+}
+
+// Remove removes an element from the set.
+func (s *BitSet) Remove(elem int) {
+	if w := elem >> 6; elem >= 0 && w < len(s.words) {
+		s.words[w] &^= 1 << (uint(elem) & 63)
+	}
+}
+
+// Has returns true if the element exists.
+func (s *BitSet) Has(elem int) bool {
+	w := elem >> 6
+	return elem >= 0 && w < len(s.words) && s.words[w]&(1<<(uint(elem)&63)) != 0
+}
+
+// Len returns the number of elements.
+func (s *BitSet) Len() int {
+	n := 0
+	for _, w := range s.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// IsEmpty returns true if the set has no elements.
+func (s *BitSet) IsEmpty() bool {
+	for _, w := range s.words {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear removes all elements, keeping the allocated words.
+func (s *BitSet) Clear() {
+	clear(s.words)
+}
+
+// NextSet returns the smallest element greater than or equal to from, so
+// for i, ok := s.NextSet(0); ok; i, ok = s.NextSet(i + 1) visits the set in order.
+func (s *BitSet) NextSet(from int) (int, bool) {
+	if from < 0 {
+		from = 0
+	}
+	w := from >> 6
+	if w >= len(s.words) {
+		return 0, false
+	}
+	// Mask off the bits below from in the first word
+	word := s.words[w] >> (uint(from) & 63)
+	if word != 0 {
+		return from + bits.TrailingZeros64(word), true
+	}
+	for w++; w < len(s.words); w++ {
+		if s.words[w] != 0 {
+			return w<<6 + bits.TrailingZeros64(s.words[w]), true
+		}
+	}
+	return 0, false
+}
+
+// Elements returns all elements in ascending order.
+func (s *BitSet) Elements() []int {
+	elems := make([]int, 0, s.Len())
+	s.Range(func(elem int) {
+		elems = append(elems, elem)
+	})
+	return elems
+}
+
+// Range iterates over all elements in ascending order.
+func (s *BitSet) Range(fn func(int)) {
+	for i, w := range s.words {
+		for w != 0 {
+			fn(i<<6 + bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+}
+
+// All returns an iterator over all elements in ascending order.
+func (s *BitSet) All() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, w := range s.words {
+			for w != 0 {
+				if !yield(i<<6 + bits.TrailingZeros64(w)) {
+					return
+				}
+				w &= w - 1
+			}
+		}
+	}
+}
+
+// Clone returns a copy of the set.
+func (s *BitSet) Clone() *BitSet {
+	return &BitSet{words: append([]uint64(nil), s.words...)}
+}
+
+// Equal returns true if both sets contain the same elements.
+func (s *BitSet) Equal(other *BitSet) bool {
+	a, b := s.words, other.words
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	for i, w := range a {
+		if i < len(b) {
+			if w != b[i] {
+				return false
+			}
+		} else if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubset returns true if s is a subset of other.
+func (s *BitSet) IsSubset(other *BitSet) bool {
+	for i, w := range s.words {
+		var o uint64
+		if i < len(other.words) {
+			o = other.words[i]
+		}
+		if w&^o != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset returns true if s is a superset of other.
+func (s *BitSet) IsSuperset(other *BitSet) bool {
+	return other.IsSubset(s)
+}
+
+// IsDisjoint returns true if s and other have no elements in common.
+func (s *BitSet) IsDisjoint(other *BitSet) bool {
+	for i := range min(len(s.words), len(other.words)) {
+		if s.words[i]&other.words[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// And returns a new set with the elements in both s and other.
+func (s *BitSet) And(other *BitSet) *BitSet {
+	n := min(len(s.words), len(other.words))
+	result := &BitSet{words: make([]uint64, n)}
+	for i := range n {
+		result.words[i] = s.words[i] & other.words[i]
+	}
+	return result
+}
+
+// Or returns a new set with the elements in s or other.
+func (s *BitSet) Or(other *BitSet) *BitSet {
+	a, b := s.words, other.words
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	result := &BitSet{words: append([]uint64(nil), a...)}
+	for i, w := range b {
+		result.words[i] |= w
+	}
+	return result
+}
+
+// AndNot returns a new set with the elements in s but not in other.
+func (s *BitSet) AndNot(other *BitSet) *BitSet {
+	result := s.Clone()
+	for i := range min(len(result.words), len(other.words)) {
+		result.words[i] &^= other.words[i]
+	}
+	return result
+}
+
+// Xor returns a new set with the elements in exactly one of s and other.
+func (s *BitSet) Xor(other *BitSet) *BitSet {
+	a, b := s.words, other.words
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	result := &BitSet{words: append([]uint64(nil), a...)}
+	for i, w := range b {
+		result.words[i] ^= w
+	}
+	return result
+}
+
+// Union is an alias for Or, matching Set.
+func (s *BitSet) Union(other *BitSet) *BitSet {
+	return s.Or(other)
+}
+
+// Intersection is an alias for And, matching Set.
+func (s *BitSet) Intersection(other *BitSet) *BitSet {
+	return s.And(other)
+}
+
+// Difference is an alias for AndNot, matching Set.
+func (s *BitSet) Difference(other *BitSet) *BitSet {
+	return s.AndNot(other)
+}
+
+// SymmetricDifference is an alias for Xor, matching Set.
+func (s *BitSet) SymmetricDifference(other *BitSet) *BitSet {
+	return s.Xor(other)
+}
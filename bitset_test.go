@@ -0,0 +1,123 @@
+package mappo
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBitSet_Basic(t *testing.T) {
+	var s BitSet // zero value is usable
+	for _, v := range []int{3, 64, 0, 200, 64} {
+		s.Add(v)
+	}
+	if s.Len() != 4 || !s.Has(64) || s.Has(65) || s.Has(-1) || s.Has(10_000) {
+		t.Fatalf("unexpected contents %v", s.Elements())
+	}
+	if !slices.Equal(s.Elements(), []int{0, 3, 64, 200}) {
+		t.Errorf("expected ascending elements, got %v", s.Elements())
+	}
+	s.Remove(3)
+	s.Remove(10_000) // out of range is a no-op
+	if s.Has(3) || s.Len() != 3 {
+		t.Errorf("expected 3 removed, got %v", s.Elements())
+	}
+
+	var visited []int
+	for i, ok := s.NextSet(0); ok; i, ok = s.NextSet(i + 1) {
+		visited = append(visited, i)
+	}
+	if !slices.Equal(visited, []int{0, 64, 200}) {
+		t.Errorf("expected NextSet walk [0 64 200], got %v", visited)
+	}
+	if i, ok := s.NextSet(65); !ok || i != 200 {
+		t.Errorf("expected 200 after 65, got %d, %v", i, ok)
+	}
+	if _, ok := s.NextSet(201); ok {
+		t.Error("expected no element after 200")
+	}
+	s.Clear()
+	if !s.IsEmpty() {
+		t.Error("expected empty set after Clear")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for negative element")
+		}
+	}()
+	s.Add(-1)
+}
+
+func TestBitSet_Ops(t *testing.T) {
+	a := NewBitSet(1, 2, 3, 130)
+	b := NewBitSet(2, 3, 4)
+	if got := a.And(b).Elements(); !slices.Equal(got, []int{2, 3}) {
+		t.Errorf("expected And [2 3], got %v", got)
+	}
+	if got := a.Or(b).Elements(); !slices.Equal(got, []int{1, 2, 3, 4, 130}) {
+		t.Errorf("expected Or [1 2 3 4 130], got %v", got)
+	}
+	if got := a.AndNot(b).Elements(); !slices.Equal(got, []int{1, 130}) {
+		t.Errorf("expected AndNot [1 130], got %v", got)
+	}
+	if !NewBitSet(2, 3).IsSubset(a) || a.IsSubset(b) {
+		t.Error("unexpected IsSubset result")
+	}
+	grown := NewBitSet(2, 500)
+	grown.Remove(500)
+	if !grown.Equal(NewBitSet(2)) || grown.Equal(b) {
+		t.Error("expected Equal to ignore trailing empty words")
+	}
+	c := a.Clone()
+	c.Add(7)
+	if a.Has(7) {
+		t.Error("expected Clone to be independent")
+	}
+}
+
+func TestBitSet_SetAlgebra(t *testing.T) {
+	a := NewBitSet(1, 2, 3, 130)
+	b := NewBitSet(2, 3, 4)
+	if !a.Union(b).Equal(a.Or(b)) || !a.Intersection(b).Equal(a.And(b)) || !a.Difference(b).Equal(a.AndNot(b)) {
+		t.Error("expected Union, Intersection and Difference to match Or, And and AndNot")
+	}
+	if got := a.Xor(b).Elements(); !slices.Equal(got, []int{1, 4, 130}) {
+		t.Errorf("expected Xor [1 4 130], got %v", got)
+	}
+	if !a.SymmetricDifference(b).Equal(b.SymmetricDifference(a)) {
+		t.Error("expected SymmetricDifference to be symmetric")
+	}
+	if !a.IsSuperset(NewBitSet(1, 130)) || a.IsSuperset(b) {
+		t.Error("unexpected IsSuperset result")
+	}
+	if a.IsDisjoint(b) || !a.IsDisjoint(NewBitSet(0, 64, 500)) {
+		t.Error("unexpected IsDisjoint result")
+	}
+	var empty BitSet
+	if !empty.Equal(NewBitSet()) || !empty.IsSubset(a) || !a.IsSuperset(&empty) {
+		t.Error("expected the zero value to behave as the empty set")
+	}
+}
+
+func TestBitSet_All(t *testing.T) {
+	s := NewBitSet(0, 63, 64, 200)
+	if got := slices.Collect(s.All()); !slices.Equal(got, []int{0, 63, 64, 200}) {
+		t.Errorf("expected ascending elements, got %v", got)
+	}
+	var first []int
+	for elem := range s.All() {
+		if elem > 63 {
+			break
+		}
+		first = append(first, elem)
+	}
+	if !slices.Equal(first, []int{0, 63}) {
+		t.Errorf("expected early stop after 63, got %v", first)
+	}
+}
+
+func BenchmarkBitSet_Add(b *testing.B) {
+	s := NewBitSet()
+	for i := 0; i < b.N; i++ {
+		s.Add(i & 0xffff)
+	}
+}
@@ -1,7 +1,9 @@
 package mappo
 
 import (
+	"container/heap"
 	"encoding/json"
+	"math/rand/v2"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,6 +17,10 @@ type Item struct {
 	Value        any          `json:"value"`
 	LastAccessed atomic.Int64 `json:"last_accessed"`
 	Exp          time.Time    `json:"exp"`
+
+	// Stale marks an item deprecated via Cache.Deprecate. It is still served
+	// until its grace period ends, but the GetOrCompute family reloads it.
+	Stale bool `json:"stale,omitempty"`
 }
 
 // MarshalJSON implements json.Marshaler.
@@ -45,43 +51,157 @@ func (it *Item) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// clone returns a shallow copy of the item.
+func (it *Item) clone() *Item {
+	cp := &Item{Value: it.Value, Exp: it.Exp, Stale: it.Stale}
+	cp.LastAccessed.Store(it.LastAccessed.Load())
+	return cp
+}
+
 // CacheOptions holds configuration for Cache.
 type CacheOptions struct {
 	MaximumSize int
 	OnDelete    func(key string, it *Item)
 	Now         func() time.Time
+	// Clock is used when Now is nil. Optional, nil uses SystemClock.
+	Clock Clock
+
+	// EventBuffer is the channel capacity for Subscribe (default 64).
+	EventBuffer int
+
+	// MaxMemoryBytes bounds the estimated memory held by entries.
+	// When set it takes precedence over MaximumSize and entries are evicted by weight.
+	MaxMemoryBytes int64
+	// SizeOf overrides the default size estimator used with MaxMemoryBytes.
+	SizeOf func(key string, it *Item) int
+
+	// TTLJitter randomizes TTLs by up to ±fraction (e.g. 0.1 for ±10%) in StoreTTL,
+	// GetOrSet and the GetOrCompute family, so entries created together don't expire together.
+	TTLJitter float64
+
+	// Invalidation broadcasts explicit deletions to other cache instances
+	// and drops keys invalidated elsewhere.
+	Invalidation Invalidator
+}
+
+// Invalidator is a transport for cross-instance invalidations (e.g. NATS, Redis pub/sub).
+// Publish is called after a local Delete, LoadAndDelete or DeleteIf removes a key.
+// Keys delivered to the Subscribe callback are dropped from the local cache without
+// being re-published, so echoes of the instance's own messages are harmless.
+// Writes are not published. Subscribe returns a func that cancels the
+// subscription; Cache.Close calls it.
+type Invalidator interface {
+	Publish(key string)
+	Subscribe(fn func(key string)) (unsubscribe func())
 }
 
 // Cache provides a high-performance concurrent cache with TTL support.
 // It uses Otter as the underlying cache for optimal performance.
 type Cache struct {
-	inner  *otter.Cache[string, *Item]
-	now    func() time.Time
-	closed atomic.Bool
-	mu     sync.RWMutex
+	inner   *otter.Cache[string, *Item]
+	counter *stats.Counter
+	bus     Invalidator
+	unsub   func()
+	jitterF float64
+	now     func() time.Time
+	closed  atomic.Bool
+	mu      sync.RWMutex
+
+	// Deletion breakdown by cause.
+	evictedSize atomic.Int64
+	expired     atomic.Int64
+	invalidated atomic.Int64
+
+	// Event subscribers, guarded by mu.
+	subs        map[uint64]chan CacheEvent
+	nextSub     uint64
+	subCount    atomic.Int32
+	eventBuffer int
 }
 
 // NewCache creates a new Cache with the given options.
 func NewCache(opt CacheOptions) *Cache {
 	nowFn := opt.Now
 	if nowFn == nil {
-		nowFn = time.Now
+		nowFn = clockOrSystem(opt.Clock).Now
+	}
+
+	eventBuffer := opt.EventBuffer
+	if eventBuffer <= 0 {
+		eventBuffer = defaultEventBuffer
 	}
 
 	counter := stats.NewCounter()
 
-	c := otter.Must(&otter.Options[string, *Item]{
+	c := &Cache{counter: counter, now: nowFn, eventBuffer: eventBuffer, bus: opt.Invalidation}
+	if opt.TTLJitter > 0 {
+		c.jitterF = min(opt.TTLJitter, 1)
+	}
+	o := &otter.Options[string, *Item]{
 		MaximumSize:   opt.MaximumSize,
 		StatsRecorder: counter,
 		OnDeletion: func(e otter.DeletionEvent[string, *Item]) {
-			if opt.OnDelete == nil || e.Value == nil {
+			if e.Value == nil {
 				return
 			}
-			opt.OnDelete(e.Key, e.Value)
+			c.onDeletion(e)
+			if opt.OnDelete != nil {
+				opt.OnDelete(e.Key, e.Value)
+			}
 		},
-	})
+	}
+	if opt.MaxMemoryBytes > 0 {
+		sizeOf := opt.SizeOf
+		if sizeOf == nil {
+			sizeOf = defaultItemWeight
+		}
+		o.MaximumSize = 0
+		o.MaximumWeight = uint64(opt.MaxMemoryBytes)
+		o.Weigher = weigher(sizeOf)
+	}
+	c.inner = otter.Must(o)
+
+	if c.bus != nil {
+		c.unsub = c.bus.Subscribe(func(key string) {
+			if c.closed.Load() {
+				return
+			}
+			c.inner.Invalidate(key)
+		})
+	}
+
+	return c
+}
 
-	return &Cache{inner: c, now: nowFn}
+// publish broadcasts a local deletion through the Invalidator, if any.
+func (c *Cache) publish(key string) {
+	if c.bus != nil {
+		c.bus.Publish(key)
+	}
+}
+
+// onDeletion translates an otter deletion into a CacheEvent.
+// Replacements are skipped since the write already emitted a set event.
+func (c *Cache) onDeletion(e otter.DeletionEvent[string, *Item]) {
+	switch e.Cause {
+	case otter.CauseReplacement:
+		return
+	case otter.CauseOverflow:
+		c.evictedSize.Add(1)
+		c.emit(CacheEventDelete, CauseEviction, e.Key, e.Value)
+	case otter.CauseExpiration:
+		c.expired.Add(1)
+		c.emit(CacheEventExpire, CauseExpiration, e.Key, e.Value)
+	default:
+		// Expired items are dropped lazily through Invalidate
+		if !e.Value.Exp.IsZero() && c.nowTime().After(e.Value.Exp) {
+			c.expired.Add(1)
+			c.emit(CacheEventExpire, CauseExpiration, e.Key, e.Value)
+			return
+		}
+		c.invalidated.Add(1)
+		c.emit(CacheEventDelete, CauseInvalidation, e.Key, e.Value)
+	}
 }
 
 // nowTime returns current time, using custom function if set.
@@ -92,6 +212,18 @@ func (c *Cache) nowTime() time.Time {
 	return time.Now()
 }
 
+// jitter spreads ttl by a random amount within ±TTLJitter.
+func (c *Cache) jitter(ttl time.Duration) time.Duration {
+	if c.jitterF == 0 || ttl <= 0 {
+		return ttl
+	}
+	delta := time.Duration(float64(ttl) * c.jitterF * (2*rand.Float64() - 1))
+	if j := ttl + delta; j > 0 {
+		return j
+	}
+	return ttl
+}
+
 // Load retrieves an item. Returns false if key doesn't exist or is expired.
 func (c *Cache) Load(key string) (*Item, bool) {
 	if c.closed.Load() {
@@ -116,6 +248,7 @@ func (c *Cache) Store(key string, it *Item) {
 		return
 	}
 	c.inner.Set(key, it)
+	c.emit(CacheEventSet, CauseWrite, key, it)
 }
 
 // StoreTTL stores an item with TTL.
@@ -124,11 +257,28 @@ func (c *Cache) StoreTTL(key string, it *Item, ttl time.Duration) {
 		return
 	}
 	if ttl > 0 {
-		it.Exp = c.nowTime().Add(ttl)
+		it.Exp = c.nowTime().Add(c.jitter(ttl))
 	} else {
 		it.Exp = time.Time{}
 	}
 	c.inner.Set(key, it)
+	c.emit(CacheEventSet, CauseWrite, key, it)
+}
+
+// StoreUntil stores an item that expires at an absolute deadline.
+// A zero deadline means no expiration. If the deadline has already passed,
+// the item is not stored and any existing value for key is removed.
+func (c *Cache) StoreUntil(key string, it *Item, deadline time.Time) {
+	if c.closed.Load() || it == nil {
+		return
+	}
+	if !deadline.IsZero() && !c.nowTime().Before(deadline) {
+		c.inner.Invalidate(key)
+		return
+	}
+	it.Exp = deadline
+	c.inner.Set(key, it)
+	c.emit(CacheEventSet, CauseWrite, key, it)
 }
 
 // LoadOrStore loads or stores an item atomically.
@@ -142,6 +292,7 @@ func (c *Cache) LoadOrStore(key string, it *Item) (*Item, bool) {
 	v, stored := c.inner.SetIfAbsent(key, it)
 	if stored {
 		// We stored it successfully
+		c.emit(CacheEventSet, CauseWrite, key, it)
 		return it, false
 	}
 
@@ -149,6 +300,7 @@ func (c *Cache) LoadOrStore(key string, it *Item) (*Item, bool) {
 	if v == nil {
 		// Inconsistent state, overwrite
 		c.inner.Set(key, it)
+		c.emit(CacheEventSet, CauseWrite, key, it)
 		return it, false
 	}
 
@@ -166,6 +318,7 @@ func (c *Cache) LoadOrStore(key string, it *Item) (*Item, bool) {
 			return current, otter.CancelOp
 		})
 		if actual == it {
+			c.emit(CacheEventSet, CauseWrite, key, it)
 			return it, false
 		}
 		return actual, true
@@ -180,6 +333,81 @@ func (c *Cache) Delete(key string) {
 		return
 	}
 	c.inner.Invalidate(key)
+	c.publish(key)
+}
+
+// Deprecate marks an item stale and keeps serving it for at most grace.
+// Load still returns the item (with Stale set) until the grace period ends,
+// while the GetOrCompute family treats it as missing and repopulates it.
+// A grace <= 0 deletes the key immediately.
+// Returns true if a live item was found.
+func (c *Cache) Deprecate(key string, grace time.Duration) bool {
+	if c.closed.Load() {
+		return false
+	}
+	if grace <= 0 {
+		_, ok := c.LoadAndDelete(key)
+		return ok
+	}
+
+	now := c.nowTime()
+	deadline := now.Add(grace)
+	deprecated := false
+	c.inner.Compute(key, func(current *Item, found bool) (*Item, otter.ComputeOp) {
+		if !found || current == nil {
+			return nil, otter.CancelOp
+		}
+		if !current.Exp.IsZero() && now.After(current.Exp) {
+			return nil, otter.InvalidateOp
+		}
+		// Replace with a copy so readers holding current never see it change.
+		stale := current.clone()
+		stale.Stale = true
+		if stale.Exp.IsZero() || deadline.Before(stale.Exp) {
+			stale.Exp = deadline
+		}
+		deprecated = true
+		return stale, otter.WriteOp
+	})
+	return deprecated
+}
+
+// DeleteIf removes entries matching the predicate and returns the number removed.
+// The predicate is re-evaluated under the key's lock before deletion, so a value
+// stored concurrently after the scan is only removed if it also matches.
+// Expired entries are skipped.
+func (c *Cache) DeleteIf(fn func(key string, it *Item) bool) int {
+	if c.closed.Load() {
+		return 0
+	}
+
+	removed := 0
+	now := c.nowTime()
+	c.inner.All()(func(key string, item *Item) bool {
+		if item == nil || (!item.Exp.IsZero() && now.After(item.Exp)) || !fn(key, item) {
+			return true
+		}
+		deleted := false
+		c.inner.Compute(key, func(current *Item, found bool) (*Item, otter.ComputeOp) {
+			if !found || current == nil {
+				return nil, otter.CancelOp
+			}
+			if !current.Exp.IsZero() && now.After(current.Exp) {
+				return current, otter.CancelOp
+			}
+			if current != item && !fn(key, current) {
+				return current, otter.CancelOp
+			}
+			deleted = true
+			return nil, otter.InvalidateOp
+		})
+		if deleted {
+			removed++
+			c.publish(key)
+		}
+		return true
+	})
+	return removed
 }
 
 // LoadAndDelete loads and deletes an item atomically.
@@ -207,6 +435,7 @@ func (c *Cache) LoadAndDelete(key string) (*Item, bool) {
 	if deleted == nil {
 		return nil, false
 	}
+	c.publish(key)
 	return deleted, true
 }
 
@@ -220,6 +449,61 @@ func GetTyped[T any](it *Item) (T, bool) {
 	return v, ok
 }
 
+// TypedItem is the generic counterpart of Item.
+// Unlike Item, JSON round-trips keep the concrete type of Value
+// instead of decoding into map[string]any or float64.
+type TypedItem[T any] struct {
+	Value        T         `json:"value"`
+	LastAccessed int64     `json:"last_accessed"`
+	Exp          time.Time `json:"exp"`
+}
+
+// Item converts the typed item into an untyped *Item suitable for Cache.
+func (t TypedItem[T]) Item() *Item {
+	it := &Item{Value: t.Value, Exp: t.Exp}
+	it.LastAccessed.Store(t.LastAccessed)
+	return it
+}
+
+// ToTyped converts an untyped *Item into a TypedItem[T].
+// Returns false if the item is nil or its value is not of type T.
+func ToTyped[T any](it *Item) (TypedItem[T], bool) {
+	v, ok := GetTyped[T](it)
+	if !ok {
+		return TypedItem[T]{}, false
+	}
+	return TypedItem[T]{
+		Value:        v,
+		LastAccessed: it.LastAccessed.Load(),
+		Exp:          it.Exp,
+	}, true
+}
+
+// LoadTyped loads a key and returns its value as T.
+// Returns false if the key doesn't exist, is expired, or holds a different type.
+func LoadTyped[T any](c *Cache, key string) (T, bool) {
+	it, ok := c.Load(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return GetTyped[T](it)
+}
+
+// LoadTypedItem loads a key and returns it as a TypedItem[T].
+func LoadTypedItem[T any](c *Cache, key string) (TypedItem[T], bool) {
+	it, ok := c.Load(key)
+	if !ok {
+		return TypedItem[T]{}, false
+	}
+	return ToTyped[T](it)
+}
+
+// StoreTyped stores a typed value with TTL. A ttl <= 0 means no expiration.
+func StoreTyped[T any](c *Cache, key string, value T, ttl time.Duration) {
+	c.StoreTTL(key, &Item{Value: value}, ttl)
+}
+
 // GetValue retrieves the value directly. Returns false if key doesn't exist or is expired.
 func (c *Cache) GetValue(key string) (any, bool) {
 	it, ok := c.Load(key)
@@ -242,7 +526,7 @@ func (c *Cache) GetOrSet(key string, value any, ttl time.Duration) (any, bool) {
 		Value: value,
 	}
 	if ttl > 0 {
-		it.Exp = c.nowTime().Add(ttl)
+		it.Exp = c.nowTime().Add(c.jitter(ttl))
 	}
 
 	actual, loaded := c.LoadOrStore(key, it)
@@ -254,20 +538,41 @@ func (c *Cache) GetOrSet(key string, value any, ttl time.Duration) (any, bool) {
 
 // GetOrCompute returns the existing value or computes and stores a new one atomically.
 func (c *Cache) GetOrCompute(key string, fn func() (any, time.Duration)) any {
+	return c.GetOrComputeKey(key, func(string) (any, time.Duration) {
+		return fn()
+	})
+}
+
+// GetOrComputeKey is like GetOrCompute but passes the key to the loader.
+func (c *Cache) GetOrComputeKey(key string, fn func(key string) (any, time.Duration)) any {
+	result, _ := c.GetOrComputeE(key, func(key string) (any, time.Duration, error) {
+		val, ttl := fn(key)
+		return val, ttl, nil
+	})
+	return result
+}
+
+// GetOrComputeE is like GetOrComputeKey but lets the loader fail.
+// When fn returns an error nothing is stored, the error is returned and the
+// load counts as a failure in Stats.
+func (c *Cache) GetOrComputeE(key string, fn func(key string) (any, time.Duration, error)) (any, error) {
 	if c.closed.Load() {
-		return nil
+		return nil, nil
 	}
 
 	// Try fast path first
-	if existing, ok := c.Load(key); ok {
-		return existing.Value
+	if existing, ok := c.Load(key); ok && !existing.Stale {
+		return existing.Value, nil
 	}
 
 	// Use Compute for atomic operation
 	var result any
+	var stored *Item
+	var err error
 	now := c.nowTime()
 	c.inner.Compute(key, func(current *Item, found bool) (*Item, otter.ComputeOp) {
-		if found && current != nil {
+		result, stored, err = nil, nil, nil
+		if found && current != nil && !current.Stale {
 			// Check expiration
 			if current.Exp.IsZero() || now.Before(current.Exp) {
 				result = current.Value
@@ -276,20 +581,113 @@ func (c *Cache) GetOrCompute(key string, fn func() (any, time.Duration)) any {
 		}
 
 		// Compute new value
-		val, ttl := fn()
+		var val any
+		var ttl time.Duration
+		c.recordLoad(func() bool {
+			val, ttl, err = fn(key)
+			return err == nil
+		})
+		if err != nil {
+			return current, otter.CancelOp
+		}
 		it := &Item{
 			Value: val,
 		}
 		if ttl > 0 {
-			it.Exp = now.Add(ttl)
+			it.Exp = now.Add(c.jitter(ttl))
 		}
 		result = val
+		stored = it
 		return it, otter.WriteOp
 	})
 
+	if stored != nil {
+		c.emit(CacheEventSet, CauseWrite, key, stored)
+	}
+	return result, err
+}
+
+// GetOrComputeMany returns values for all keys, loading the missing ones with a single call to fn.
+// fn receives the missing keys (deduplicated) and returns the values it found plus a shared TTL.
+// Keys fn does not return are absent from the result. Values stored concurrently by
+// other callers while fn runs take precedence over the loaded ones.
+func (c *Cache) GetOrComputeMany(keys []string, fn func(missing []string) (map[string]any, time.Duration)) map[string]any {
+	if c.closed.Load() {
+		return nil
+	}
+
+	result := make(map[string]any, len(keys))
+	var missing []string
+	seen := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		if existing, ok := c.Load(key); ok && !existing.Stale {
+			result[key] = existing.Value
+			continue
+		}
+		missing = append(missing, key)
+	}
+	if len(missing) == 0 {
+		return result
+	}
+
+	var loaded map[string]any
+	var ttl time.Duration
+	c.recordLoad(func() bool {
+		loaded, ttl = fn(missing)
+		// Nothing will be cached if fn found none of the keys
+		for _, key := range missing {
+			if _, ok := loaded[key]; ok {
+				return true
+			}
+		}
+		return false
+	})
+
+	now := c.nowTime()
+	for _, key := range missing {
+		val, ok := loaded[key]
+		if !ok {
+			continue
+		}
+		it := &Item{Value: val}
+		if ttl > 0 {
+			it.Exp = now.Add(c.jitter(ttl))
+		}
+		actual, _ := c.inner.Compute(key, func(current *Item, found bool) (*Item, otter.ComputeOp) {
+			if found && current != nil && !current.Stale && (current.Exp.IsZero() || now.Before(current.Exp)) {
+				return current, otter.CancelOp
+			}
+			return it, otter.WriteOp
+		})
+		if actual == it {
+			c.emit(CacheEventSet, CauseWrite, key, it)
+		}
+		if actual != nil {
+			result[key] = actual.Value
+		}
+	}
 	return result
 }
 
+// recordLoad runs fn and records its duration as a load success, or as a
+// load failure if fn reports nothing to cache or panics.
+func (c *Cache) recordLoad(fn func() bool) {
+	start := time.Now()
+	ok := false
+	defer func() {
+		if ok {
+			c.counter.RecordLoadSuccess(time.Since(start))
+		} else {
+			c.counter.RecordLoadFailure(time.Since(start))
+		}
+	}()
+	ok = fn()
+}
+
 // Has returns true if the key exists and is not expired.
 func (c *Cache) Has(key string) bool {
 	_, ok := c.Load(key)
@@ -329,6 +727,32 @@ func (c *Cache) Range(fn func(key string, item *Item) bool) {
 	})
 }
 
+// Snapshot returns a copy of all non-expired items.
+// The copy is weakly consistent, not point-in-time: it is built with Range, so
+// each entry reflects some state of the cache during the iteration, and writes
+// made meanwhile may or may not be included. Items are copied, so writes, TTL
+// refreshes or touches after Snapshot returns are not reflected.
+func (c *Cache) Snapshot() map[string]*Item {
+	out := make(map[string]*Item, c.Len())
+	c.Range(func(key string, item *Item) bool {
+		out[key] = item.clone()
+		return true
+	})
+	return out
+}
+
+// RangeSnapshot iterates over a copy of the cache taken by Snapshot, with the
+// same weak consistency. Unlike Range, fn does not observe entries added or
+// removed while it runs, which suits long-running exports.
+// Return false to stop iteration.
+func (c *Cache) RangeSnapshot(fn func(key string, item *Item) bool) {
+	for key, item := range c.Snapshot() {
+		if !fn(key, item) {
+			return
+		}
+	}
+}
+
 // Keys returns all keys in the cache.
 func (c *Cache) Keys() []string {
 	keys := make([]string, 0, c.Len())
@@ -339,34 +763,96 @@ func (c *Cache) Keys() []string {
 	return keys
 }
 
+// KeysPage returns up to limit keys in lexicographic order starting at cursor,
+// plus the cursor for the next page ("" when there are no more keys).
+// Pass "" to start from the beginning. Cursors are opaque: the next one is
+// the smallest string after the page's last key, so it is never "" while
+// keys remain, even when "" itself is a key. Memory use is bounded by limit,
+// and paging stays consistent when entries are added or removed between calls.
+func (c *Cache) KeysPage(cursor string, limit int) ([]string, string) {
+	if limit <= 0 {
+		return nil, ""
+	}
+
+	// Keep the smallest limit keys from cursor on in a max-heap.
+	h := &keyHeap{}
+	more := false
+	c.Range(func(key string, _ *Item) bool {
+		if key < cursor {
+			return true
+		}
+		if h.Len() < limit {
+			heap.Push(h, key)
+			return true
+		}
+		more = true
+		if key < (*h)[0] {
+			(*h)[0] = key
+			heap.Fix(h, 0)
+		}
+		return true
+	})
+
+	keys := make([]string, h.Len())
+	for i := len(keys) - 1; i >= 0; i-- {
+		keys[i] = heap.Pop(h).(string)
+	}
+	if !more || len(keys) == 0 {
+		return keys, ""
+	}
+	return keys, keys[len(keys)-1] + "\x00"
+}
+
+// keyHeap is a max-heap of strings used by KeysPage.
+type keyHeap []string
+
+func (h keyHeap) Len() int           { return len(h) }
+func (h keyHeap) Less(i, j int) bool { return h[i] > h[j] }
+func (h keyHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *keyHeap) Push(x any)        { *h = append(*h, x.(string)) }
+func (h *keyHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
 // RefreshTTL updates the TTL of an existing item without changing its value.
 // Returns true if the item was found and updated.
 func (c *Cache) RefreshTTL(key string, ttl time.Duration) bool {
+	var deadline time.Time
+	if ttl > 0 {
+		deadline = c.nowTime().Add(ttl)
+	}
+	return c.RefreshUntil(key, deadline)
+}
+
+// RefreshUntil sets an absolute expiration deadline on an existing item without changing its value.
+// A zero deadline removes the expiration. Returns true if the item was found and updated.
+func (c *Cache) RefreshUntil(key string, deadline time.Time) bool {
 	if c.closed.Load() {
 		return false
 	}
+	return c.modifyLive(key, c.nowTime(), func(it *Item) {
+		it.Exp = deadline
+	})
+}
 
-	updated := false
+// RefreshTTLMany updates the TTL of many existing items in one pass.
+// All items share the same deadline. Returns the number of items updated.
+func (c *Cache) RefreshTTLMany(keys []string, ttl time.Duration) int {
+	if c.closed.Load() {
+		return 0
+	}
 	now := c.nowTime()
-	c.inner.Compute(key, func(current *Item, found bool) (*Item, otter.ComputeOp) {
-		if !found || current == nil {
-			return nil, otter.CancelOp
-		}
-		// Check if expired
-		if !current.Exp.IsZero() && now.After(current.Exp) {
-			return nil, otter.InvalidateOp // Delete expired
-		}
-
-		if ttl > 0 {
-			current.Exp = now.Add(ttl)
-		} else {
-			current.Exp = time.Time{}
-		}
-		updated = true
-		return current, otter.WriteOp
+	var deadline time.Time
+	if ttl > 0 {
+		deadline = now.Add(ttl)
+	}
+	return c.modifyLiveMany(keys, now, func(it *Item) {
+		it.Exp = deadline
 	})
-
-	return updated
 }
 
 // Touch updates the LastAccessed timestamp without fetching the full value.
@@ -375,22 +861,52 @@ func (c *Cache) Touch(key string) bool {
 	if c.closed.Load() {
 		return false
 	}
+	now := c.nowTime()
+	return c.modifyLive(key, now, func(it *Item) {
+		it.LastAccessed.Store(now.UnixNano())
+	})
+}
 
-	touched := false
+// TouchMany updates the LastAccessed timestamp of many items in one pass.
+// Returns the number of items touched.
+func (c *Cache) TouchMany(keys []string) int {
+	if c.closed.Load() {
+		return 0
+	}
 	now := c.nowTime()
+	return c.modifyLiveMany(keys, now, func(it *Item) {
+		it.LastAccessed.Store(now.UnixNano())
+	})
+}
+
+// modifyLive atomically applies fn to the item at key if it exists and is not expired.
+// Expired items are deleted. Returns true if fn was applied.
+func (c *Cache) modifyLive(key string, now time.Time, fn func(it *Item)) bool {
+	modified := false
 	c.inner.Compute(key, func(current *Item, found bool) (*Item, otter.ComputeOp) {
 		if !found || current == nil {
 			return nil, otter.CancelOp
 		}
+		// Check if expired
 		if !current.Exp.IsZero() && now.After(current.Exp) {
-			return nil, otter.InvalidateOp
+			return nil, otter.InvalidateOp // Delete expired
 		}
-		current.LastAccessed.Store(now.UnixNano())
-		touched = true
+		fn(current)
+		modified = true
 		return current, otter.WriteOp
 	})
+	return modified
+}
 
-	return touched
+// modifyLiveMany applies modifyLive to each key and returns how many were modified.
+func (c *Cache) modifyLiveMany(keys []string, now time.Time, fn func(it *Item)) int {
+	n := 0
+	for _, key := range keys {
+		if c.modifyLive(key, now, fn) {
+			n++
+		}
+	}
+	return n
 }
 
 // CacheStats holds cache statistics.
@@ -400,6 +916,27 @@ type CacheStats struct {
 	Evictions int64
 	Size      int64
 	Capacity  int64
+
+	// Loader statistics from GetOrCompute and friends. A load fails when the
+	// loader panics, returns an error or, in GetOrComputeMany, finds none of
+	// the missing keys.
+	LoadSuccessCount int64
+	LoadFailureCount int64
+	TotalLoadTime    time.Duration
+
+	// Deletion breakdown by cause.
+	EvictionsBySize int64 // Evicted to respect MaximumSize/MaxMemoryBytes
+	Expirations     int64 // Removed after their TTL passed
+	Invalidations   int64 // Removed explicitly via Delete, Clear, etc.
+}
+
+// AverageLoadPenalty returns the average time spent loading new values.
+func (s CacheStats) AverageLoadPenalty() time.Duration {
+	loads := s.LoadSuccessCount + s.LoadFailureCount
+	if loads == 0 {
+		return 0
+	}
+	return s.TotalLoadTime / time.Duration(loads)
 }
 
 // Stats returns cache statistics.
@@ -409,11 +946,17 @@ func (c *Cache) Stats() CacheStats {
 	}
 	stats := c.inner.Stats()
 	return CacheStats{
-		Hits:      int64(stats.Hits),
-		Misses:    int64(stats.Misses),
-		Evictions: int64(stats.Evictions),
-		Size:      int64(c.Len()),
-		Capacity:  int64(c.inner.GetMaximum()),
+		Hits:             int64(stats.Hits),
+		Misses:           int64(stats.Misses),
+		Evictions:        int64(stats.Evictions),
+		Size:             int64(c.Len()),
+		Capacity:         int64(c.inner.GetMaximum()),
+		LoadSuccessCount: int64(stats.LoadSuccesses),
+		LoadFailureCount: int64(stats.LoadFailures),
+		TotalLoadTime:    stats.TotalLoadTime,
+		EvictionsBySize:  c.evictedSize.Load(),
+		Expirations:      c.expired.Load(),
+		Invalidations:    c.invalidated.Load(),
 	}
 }
 
@@ -422,7 +965,11 @@ func (c *Cache) Stats() CacheStats {
 // we just mark it as closed to prevent further operations.
 func (c *Cache) Close() error {
 	if c.closed.CompareAndSwap(false, true) {
+		if c.unsub != nil {
+			c.unsub()
+		}
 		c.inner.InvalidateAll()
+		c.closeSubscribers()
 	}
 	return nil
 }
@@ -0,0 +1,131 @@
+package mappo
+
+// defaultEventBuffer is the channel capacity used by Subscribe when
+// CacheOptions.EventBuffer is not set.
+const defaultEventBuffer = 64
+
+// CacheEventType identifies the kind of change a CacheEvent describes.
+type CacheEventType int
+
+const (
+	// CacheEventSet is emitted when a key is written.
+	CacheEventSet CacheEventType = iota + 1
+	// CacheEventDelete is emitted when a key is removed explicitly or evicted.
+	CacheEventDelete
+	// CacheEventExpire is emitted when a key is removed because its TTL passed.
+	CacheEventExpire
+)
+
+// String returns the event type name.
+func (t CacheEventType) String() string {
+	switch t {
+	case CacheEventSet:
+		return "set"
+	case CacheEventDelete:
+		return "delete"
+	case CacheEventExpire:
+		return "expire"
+	default:
+		return "unknown"
+	}
+}
+
+// CacheEventCause explains why a CacheEvent was emitted.
+type CacheEventCause int
+
+const (
+	// CauseWrite means the caller stored a value.
+	CauseWrite CacheEventCause = iota + 1
+	// CauseInvalidation means the caller deleted the key or cleared the cache.
+	CauseInvalidation
+	// CauseEviction means the key was evicted to respect size limits.
+	CauseEviction
+	// CauseExpiration means the key's TTL passed.
+	CauseExpiration
+)
+
+// String returns the cause name.
+func (c CacheEventCause) String() string {
+	switch c {
+	case CauseWrite:
+		return "write"
+	case CauseInvalidation:
+		return "invalidation"
+	case CauseEviction:
+		return "eviction"
+	case CauseExpiration:
+		return "expiration"
+	default:
+		return "unknown"
+	}
+}
+
+// CacheEvent describes a single change to a Cache.
+type CacheEvent struct {
+	Type  CacheEventType
+	Cause CacheEventCause
+	Key   string
+	Item  *Item
+}
+
+// Subscribe returns a channel receiving change events and a cancel func.
+// Delivery is non-blocking: events are dropped for subscribers whose buffer is full,
+// so slow consumers never stall cache operations.
+// The channel is closed by cancel or when the cache is closed.
+func (c *Cache) Subscribe() (<-chan CacheEvent, func()) {
+	ch := make(chan CacheEvent, c.eventBuffer)
+
+	c.mu.Lock()
+	if c.closed.Load() {
+		c.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	if c.subs == nil {
+		c.subs = make(map[uint64]chan CacheEvent)
+	}
+	c.nextSub++
+	id := c.nextSub
+	c.subs[id] = ch
+	c.subCount.Add(1)
+	c.mu.Unlock()
+
+	cancel := func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if sub, ok := c.subs[id]; ok {
+			delete(c.subs, id)
+			c.subCount.Add(-1)
+			close(sub)
+		}
+	}
+	return ch, cancel
+}
+
+// emit delivers an event to all subscribers without blocking.
+func (c *Cache) emit(typ CacheEventType, cause CacheEventCause, key string, it *Item) {
+	if c.subCount.Load() == 0 {
+		return
+	}
+	ev := CacheEvent{Type: typ, Cause: cause, Key: key, Item: it}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, ch := range c.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// closeSubscribers closes and removes all subscriber channels.
+func (c *Cache) closeSubscribers() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, ch := range c.subs {
+		delete(c.subs, id)
+		close(ch)
+	}
+	c.subCount.Store(0)
+}
@@ -1,11 +1,15 @@
 package mappo
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+	"unsafe"
 )
 
 func TestCache_LoadStore(t *testing.T) {
@@ -257,3 +261,547 @@ func BenchmarkCache_Load(b *testing.B) {
 		c.Load(fmt.Sprintf("key%d", i))
 	}
 }
+
+func TestCache_Typed(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	c := NewCache(CacheOptions{MaximumSize: 10})
+	StoreTyped(c, "u", user{Name: "ann", Age: 30}, 0)
+
+	u, ok := LoadTyped[user](c, "u")
+	if !ok || u.Name != "ann" {
+		t.Errorf("expected typed load, got %+v ok=%v", u, ok)
+	}
+	if _, ok := LoadTyped[string](c, "u"); ok {
+		t.Error("expected type mismatch to fail")
+	}
+	if _, ok := LoadTyped[user](c, "missing"); ok {
+		t.Error("expected missing key to fail")
+	}
+
+	ti, ok := LoadTypedItem[user](c, "u")
+	if !ok {
+		t.Fatal("expected typed item")
+	}
+	b, err := json.Marshal(ti)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back TypedItem[user]
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Value != ti.Value {
+		t.Errorf("expected %+v after round-trip, got %+v", ti.Value, back.Value)
+	}
+	if v, ok := GetTyped[user](back.Item()); !ok || v.Age != 30 {
+		t.Error("expected Item() to preserve value")
+	}
+}
+
+func TestCache_Subscribe(t *testing.T) {
+	now := time.Now()
+	c := NewCache(CacheOptions{
+		MaximumSize: 10,
+		Now:         func() time.Time { return now },
+	})
+	events, cancel := c.Subscribe()
+	defer cancel()
+
+	next := func() CacheEvent {
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+			return CacheEvent{}
+		}
+	}
+
+	c.Store("a", &Item{Value: 1})
+	if ev := next(); ev.Type != CacheEventSet || ev.Key != "a" || ev.Cause != CauseWrite {
+		t.Errorf("expected set event for a, got %+v", ev)
+	}
+
+	c.Delete("a")
+	if ev := next(); ev.Type != CacheEventDelete || ev.Cause != CauseInvalidation {
+		t.Errorf("expected delete event, got %+v", ev)
+	}
+
+	c.StoreTTL("b", &Item{Value: 2}, time.Second)
+	next()
+	now = now.Add(2 * time.Second)
+	c.Load("b")
+	if ev := next(); ev.Type != CacheEventExpire || ev.Key != "b" {
+		t.Errorf("expected expire event for b, got %+v", ev)
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("expected channel closed after cancel")
+	}
+}
+
+func TestCache_SubscribeClose(t *testing.T) {
+	c := NewCache(CacheOptions{MaximumSize: 10})
+	events, _ := c.Subscribe()
+	_ = c.Close()
+	for range events {
+	}
+	late, _ := c.Subscribe()
+	if _, ok := <-late; ok {
+		t.Error("expected closed channel after Close")
+	}
+}
+
+func TestEstimateSize(t *testing.T) {
+	type payload struct {
+		Name string
+		Data []byte
+		Tags map[string]int
+		Next *payload
+	}
+	if n := EstimateSize("hello"); n < 5 {
+		t.Errorf("expected string size >= 5, got %d", n)
+	}
+	if n := EstimateSize(make([]byte, 1024)); n < 1024 {
+		t.Errorf("expected []byte size >= 1024, got %d", n)
+	}
+	p := &payload{Name: "abc", Data: make([]byte, 100), Tags: map[string]int{"x": 1}}
+	p.Next = p // cycles must terminate
+	if n := EstimateSize(p); n < 100 {
+		t.Errorf("expected struct size >= 100, got %d", n)
+	}
+	if EstimateSize(nil) != 0 {
+		t.Error("expected nil size 0")
+	}
+	// Scalars match the platform's sizes, not 64-bit ones
+	for _, v := range []any{int(1), uint(1), uintptr(1), int32(1), complex64(1), 1.5} {
+		if n, want := EstimateSize(v), int(reflect.TypeOf(v).Size()); n != want {
+			t.Errorf("expected %T size %d, got %d", v, want, n)
+		}
+	}
+	if n, want := EstimateSize("hello"), int(unsafe.Sizeof(""))+5; n != want {
+		t.Errorf("expected string size %d, got %d", want, n)
+	}
+}
+
+func TestCache_MaxMemoryBytes(t *testing.T) {
+	c := NewCache(CacheOptions{
+		MaxMemoryBytes: 64 * 1024,
+		SizeOf: func(_ string, it *Item) int {
+			return len(it.Value.([]byte))
+		},
+	})
+	for i := 0; i < 100; i++ {
+		c.Store(fmt.Sprintf("key%d", i), &Item{Value: make([]byte, 4096)})
+	}
+	c.inner.CleanUp()
+	if n := c.Len(); n > 16 {
+		t.Errorf("expected at most 16 entries within budget, got %d", n)
+	}
+	if c.Stats().Capacity != 64*1024 {
+		t.Error("expected capacity to report memory budget")
+	}
+}
+
+func TestCache_StatsLoadsAndCauses(t *testing.T) {
+	now := time.Now()
+	c := NewCache(CacheOptions{
+		MaximumSize: 10,
+		Now:         func() time.Time { return now },
+	})
+	c.GetOrCompute("a", func() (any, time.Duration) {
+		time.Sleep(time.Millisecond)
+		return 1, 0
+	})
+	func() {
+		defer func() { _ = recover() }()
+		c.GetOrCompute("b", func() (any, time.Duration) { panic("boom") })
+	}()
+
+	c.Delete("a")
+	c.StoreTTL("c", &Item{Value: 3}, time.Second)
+	now = now.Add(2 * time.Second)
+	c.Load("c")
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s := c.Stats()
+		if s.Invalidations == 1 && s.Expirations == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	s := c.Stats()
+	if s.LoadSuccessCount != 1 || s.LoadFailureCount != 1 {
+		t.Errorf("expected 1 success and 1 failure, got %d/%d", s.LoadSuccessCount, s.LoadFailureCount)
+	}
+	if s.TotalLoadTime < time.Millisecond || s.AverageLoadPenalty() <= 0 {
+		t.Errorf("expected load time recorded, got %v", s.TotalLoadTime)
+	}
+	if s.Invalidations != 1 || s.Expirations != 1 {
+		t.Errorf("expected 1 invalidation and 1 expiration, got %d/%d", s.Invalidations, s.Expirations)
+	}
+}
+
+func TestCache_GetOrComputeKey(t *testing.T) {
+	c := NewCache(CacheOptions{MaximumSize: 10})
+	val := c.GetOrComputeKey("user:1", func(key string) (any, time.Duration) {
+		return "loaded " + key, 0
+	})
+	if val != "loaded user:1" {
+		t.Errorf("expected key passed to loader, got %v", val)
+	}
+}
+
+func TestCache_GetOrComputeE(t *testing.T) {
+	c := NewCache(CacheOptions{MaximumSize: 10})
+	errDown := errors.New("db down")
+
+	val, err := c.GetOrComputeE("user:1", func(string) (any, time.Duration, error) {
+		return nil, 0, errDown
+	})
+	if !errors.Is(err, errDown) || val != nil {
+		t.Errorf("expected loader error, got %v %v", val, err)
+	}
+	if c.Has("user:1") {
+		t.Error("expected failed load not cached")
+	}
+
+	val, err = c.GetOrComputeE("user:1", func(key string) (any, time.Duration, error) {
+		return "loaded " + key, 0, nil
+	})
+	if err != nil || val != "loaded user:1" {
+		t.Errorf("expected loaded value, got %v %v", val, err)
+	}
+}
+
+func TestCache_LoadFailures(t *testing.T) {
+	c := NewCache(CacheOptions{MaximumSize: 10})
+
+	c.GetOrComputeE("a", func(string) (any, time.Duration, error) {
+		return nil, 0, errors.New("boom")
+	})
+	// None of the keys found, nothing to cache
+	c.GetOrComputeMany([]string{"b", "c"}, func([]string) (map[string]any, time.Duration) {
+		return nil, 0
+	})
+	c.GetOrComputeMany([]string{"b", "c"}, func([]string) (map[string]any, time.Duration) {
+		return map[string]any{"b": 1}, 0
+	})
+	c.GetOrCompute("d", func() (any, time.Duration) { return 1, 0 })
+
+	s := c.Stats()
+	if s.LoadSuccessCount != 2 || s.LoadFailureCount != 2 {
+		t.Errorf("expected 2 successes and 2 failures, got %d/%d", s.LoadSuccessCount, s.LoadFailureCount)
+	}
+}
+
+func TestCache_GetOrComputeMany(t *testing.T) {
+	c := NewCache(CacheOptions{MaximumSize: 10})
+	c.Store("a", &Item{Value: "cached"})
+
+	calls := 0
+	var got []string
+	result := c.GetOrComputeMany([]string{"a", "b", "c", "b"}, func(missing []string) (map[string]any, time.Duration) {
+		calls++
+		got = missing
+		return map[string]any{"b": "loaded b"}, time.Minute
+	})
+	if calls != 1 {
+		t.Errorf("expected single loader call, got %d", calls)
+	}
+	if len(got) != 2 {
+		t.Errorf("expected 2 missing keys, got %v", got)
+	}
+	if result["a"] != "cached" || result["b"] != "loaded b" {
+		t.Errorf("unexpected result %v", result)
+	}
+	if _, ok := result["c"]; ok {
+		t.Error("expected c absent when loader omits it")
+	}
+	if !c.Has("b") {
+		t.Error("expected loaded value stored")
+	}
+}
+
+func TestCache_KeysPage(t *testing.T) {
+	c := NewCache(CacheOptions{MaximumSize: 100})
+	for i := 0; i < 25; i++ {
+		c.Store(fmt.Sprintf("key%02d", i), &Item{Value: i})
+	}
+
+	var all []string
+	cursor := ""
+	pages := 0
+	for {
+		keys, next := c.KeysPage(cursor, 10)
+		all = append(all, keys...)
+		pages++
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if pages != 3 {
+		t.Errorf("expected 3 pages, got %d", pages)
+	}
+	if len(all) != 25 {
+		t.Fatalf("expected 25 keys, got %d", len(all))
+	}
+	for i, k := range all {
+		if k != fmt.Sprintf("key%02d", i) {
+			t.Errorf("expected sorted keys, got %s at %d", k, i)
+		}
+	}
+	if keys, next := c.KeysPage("", 0); keys != nil || next != "" {
+		t.Error("expected empty page for limit 0")
+	}
+
+	// "" sorts first, so a one-key page can end on it
+	c.Store("", &Item{Value: -1})
+	all = all[:0]
+	for cursor, more := "", true; more; {
+		var keys []string
+		keys, cursor = c.KeysPage(cursor, 1)
+		all = append(all, keys...)
+		more = cursor != ""
+	}
+	if len(all) != 26 || all[0] != "" || all[25] != "key24" {
+		t.Errorf("expected all 26 keys paged with an empty key, got %q", all)
+	}
+}
+
+func TestCache_StoreUntil(t *testing.T) {
+	now := time.Now()
+	c := NewCache(CacheOptions{
+		MaximumSize: 10,
+		Now:         func() time.Time { return now },
+	})
+	deadline := now.Add(1500 * time.Millisecond)
+	c.StoreUntil("key", &Item{Value: "v"}, deadline)
+	it, ok := c.Load("key")
+	if !ok || !it.Exp.Equal(deadline) {
+		t.Fatalf("expected exact deadline, got %v ok=%v", it, ok)
+	}
+
+	later := deadline.Add(time.Minute)
+	if !c.RefreshUntil("key", later) {
+		t.Error("expected refresh to succeed")
+	}
+	now = deadline.Add(time.Second)
+	if !c.Has("key") {
+		t.Error("expected key alive after RefreshUntil")
+	}
+
+	c.StoreUntil("key", &Item{Value: "stale"}, now.Add(-time.Second))
+	if c.Has("key") {
+		t.Error("expected past deadline to remove key")
+	}
+	if c.RefreshUntil("missing", later) {
+		t.Error("expected refresh of missing key to fail")
+	}
+}
+
+func TestCache_RefreshTTLManyTouchMany(t *testing.T) {
+	now := time.Now()
+	c := NewCache(CacheOptions{
+		MaximumSize: 10,
+		Now:         func() time.Time { return now },
+	})
+	for _, k := range []string{"a", "b", "c"} {
+		c.StoreTTL(k, &Item{Value: k}, time.Second)
+	}
+
+	if n := c.RefreshTTLMany([]string{"a", "b", "missing"}, time.Minute); n != 2 {
+		t.Errorf("expected 2 refreshed, got %d", n)
+	}
+	now = now.Add(2 * time.Second)
+	if !c.Has("a") || !c.Has("b") {
+		t.Error("expected refreshed keys alive")
+	}
+	if c.Has("c") {
+		t.Error("expected c expired")
+	}
+
+	if n := c.TouchMany([]string{"a", "b", "c"}); n != 2 {
+		t.Errorf("expected 2 touched, got %d", n)
+	}
+	it, _ := c.Load("a")
+	if it.LastAccessed.Load() != now.UnixNano() {
+		t.Error("expected LastAccessed updated")
+	}
+}
+
+func TestCache_DeleteIf(t *testing.T) {
+	c := NewCache(CacheOptions{MaximumSize: 100})
+	for i := 0; i < 10; i++ {
+		c.Store(fmt.Sprintf("key%d", i), &Item{Value: i})
+	}
+	n := c.DeleteIf(func(_ string, it *Item) bool {
+		return it.Value.(int)%2 == 0
+	})
+	if n != 5 {
+		t.Errorf("expected 5 removed, got %d", n)
+	}
+	if c.Has("key0") || !c.Has("key1") {
+		t.Error("expected only even values removed")
+	}
+}
+
+// memoryBus is an in-process Invalidator fanning out to all subscribers.
+type memoryBus struct {
+	mu   sync.Mutex
+	subs map[int]func(string)
+	next int
+}
+
+func (b *memoryBus) Publish(key string) {
+	b.mu.Lock()
+	subs := make([]func(string), 0, len(b.subs))
+	for _, fn := range b.subs {
+		subs = append(subs, fn)
+	}
+	b.mu.Unlock()
+	for _, fn := range subs {
+		fn(key)
+	}
+}
+
+func (b *memoryBus) Subscribe(fn func(string)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs == nil {
+		b.subs = make(map[int]func(string))
+	}
+	id := b.next
+	b.next++
+	b.subs[id] = fn
+	return func() {
+		b.mu.Lock()
+		delete(b.subs, id)
+		b.mu.Unlock()
+	}
+}
+
+func (b *memoryBus) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+func TestCache_Invalidation(t *testing.T) {
+	bus := &memoryBus{}
+	c1 := NewCache(CacheOptions{MaximumSize: 10, Invalidation: bus})
+	c2 := NewCache(CacheOptions{MaximumSize: 10, Invalidation: bus})
+
+	c1.Store("key", &Item{Value: 1})
+	c2.Store("key", &Item{Value: 1})
+	c1.Delete("key")
+	if c2.Has("key") {
+		t.Error("expected remote invalidation to drop key")
+	}
+
+	c1.Store("other", &Item{Value: 2})
+	c2.Store("other", &Item{Value: 2})
+	if !c1.Has("other") {
+		t.Error("expected writes not to be published")
+	}
+	c2.LoadAndDelete("other")
+	if c1.Has("other") {
+		t.Error("expected LoadAndDelete to publish")
+	}
+
+	c1.Close()
+	if n := bus.len(); n != 1 {
+		t.Errorf("expected Close to unsubscribe, got %d subscribers", n)
+	}
+}
+
+func TestCache_TTLJitter(t *testing.T) {
+	now := time.Now()
+	c := NewCache(CacheOptions{
+		MaximumSize: 100,
+		TTLJitter:   0.5,
+		Now:         func() time.Time { return now },
+	})
+	distinct := make(map[time.Time]struct{})
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key%d", i)
+		c.StoreTTL(key, &Item{Value: i}, time.Minute)
+		it, _ := c.Load(key)
+		d := it.Exp.Sub(now)
+		if d < 30*time.Second || d > 90*time.Second {
+			t.Errorf("expected TTL within ±50%%, got %v", d)
+		}
+		distinct[it.Exp] = struct{}{}
+	}
+	if len(distinct) < 2 {
+		t.Error("expected jittered expirations to differ")
+	}
+}
+
+func TestCache_Snapshot(t *testing.T) {
+	c := NewCache(CacheOptions{MaximumSize: 100})
+	for i := 0; i < 5; i++ {
+		c.Store(fmt.Sprintf("key%d", i), &Item{Value: i})
+	}
+
+	seen := 0
+	c.RangeSnapshot(func(key string, _ *Item) bool {
+		if seen == 0 {
+			c.Store("added", &Item{Value: -1})
+			c.Delete("key0")
+			c.Delete("key1")
+		}
+		seen++
+		return true
+	})
+	if seen != 5 {
+		t.Errorf("expected 5 entries from snapshot, got %d", seen)
+	}
+
+	snap := c.Snapshot()
+	if _, ok := snap["added"]; !ok || len(snap) != 4 {
+		t.Errorf("expected new snapshot to reflect changes, got %d entries", len(snap))
+	}
+	c.RefreshTTL("key2", time.Hour)
+	if !snap["key2"].Exp.IsZero() {
+		t.Error("expected snapshot items isolated from later writes")
+	}
+}
+
+func TestCache_Deprecate(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewCache(CacheOptions{MaximumSize: 10, Clock: clk})
+	c.Store("cfg", &Item{Value: "v1"})
+
+	if !c.Deprecate("cfg", time.Minute) {
+		t.Fatal("expected deprecate to find key")
+	}
+	it, ok := c.Load("cfg")
+	if !ok || !it.Stale || it.Value != "v1" {
+		t.Fatalf("expected stale v1 served during grace, got %+v ok=%v", it, ok)
+	}
+
+	val := c.GetOrCompute("cfg", func() (any, time.Duration) { return "v2", 0 })
+	if val != "v2" {
+		t.Errorf("expected loader to repopulate stale key, got %v", val)
+	}
+	if it, _ := c.Load("cfg"); it.Stale {
+		t.Error("expected fresh item not stale")
+	}
+
+	c.Deprecate("cfg", time.Minute)
+	clk.Advance(2 * time.Minute)
+	if c.Has("cfg") {
+		t.Error("expected stale item gone after grace")
+	}
+	if c.Deprecate("missing", time.Minute) {
+		t.Error("expected deprecate of missing key to fail")
+	}
+}
@@ -0,0 +1,138 @@
+package mappo
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"unsafe"
+)
+
+// maxEstimateDepth bounds recursion when estimating nested values.
+const maxEstimateDepth = 32
+
+// itemOverhead is the fixed cost of an Item wrapper.
+var itemOverhead = int(unsafe.Sizeof(Item{}))
+
+// EstimateSize returns an approximate number of bytes retained by v.
+// Strings, byte slices and numeric types are measured exactly; other values
+// (structs, slices, maps, pointers) are walked via reflection.
+// Shared pointers are counted once.
+func EstimateSize(v any) int {
+	switch x := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return int(unsafe.Sizeof(x)) + len(x)
+	case []byte:
+		return int(unsafe.Sizeof(x)) + cap(x)
+	case bool, int8, uint8:
+		return 1
+	case int16, uint16:
+		return 2
+	case int32, uint32, float32:
+		return 4
+	case int64, uint64, float64, complex64:
+		return 8
+	case complex128:
+		return 16
+	case int, uint:
+		return strconv.IntSize / 8
+	case uintptr:
+		return int(unsafe.Sizeof(x))
+	}
+	rv := reflect.ValueOf(v)
+	seen := make(map[uintptr]struct{})
+	return int(rv.Type().Size()) + estimateIndirect(rv, seen, 0)
+}
+
+// estimateIndirect returns bytes referenced by rv beyond its inline size.
+func estimateIndirect(rv reflect.Value, seen map[uintptr]struct{}, depth int) int {
+	if depth > maxEstimateDepth || !rv.IsValid() {
+		return 0
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.Len()
+	case reflect.Pointer:
+		if rv.IsNil() || visited(rv.Pointer(), seen) {
+			return 0
+		}
+		elem := rv.Elem()
+		return int(elem.Type().Size()) + estimateIndirect(elem, seen, depth+1)
+	case reflect.Interface:
+		if rv.IsNil() {
+			return 0
+		}
+		elem := rv.Elem()
+		return int(elem.Type().Size()) + estimateIndirect(elem, seen, depth+1)
+	case reflect.Slice:
+		if rv.IsNil() || visited(rv.Pointer(), seen) {
+			return 0
+		}
+		n := rv.Cap() * int(rv.Type().Elem().Size())
+		for i := 0; i < rv.Len(); i++ {
+			n += estimateIndirect(rv.Index(i), seen, depth+1)
+		}
+		return n
+	case reflect.Array:
+		n := 0
+		for i := 0; i < rv.Len(); i++ {
+			n += estimateIndirect(rv.Index(i), seen, depth+1)
+		}
+		return n
+	case reflect.Map:
+		if rv.IsNil() || visited(rv.Pointer(), seen) {
+			return 0
+		}
+		kt, vt := rv.Type().Key(), rv.Type().Elem()
+		n := rv.Len() * int(kt.Size()+vt.Size())
+		iter := rv.MapRange()
+		for iter.Next() {
+			n += estimateIndirect(iter.Key(), seen, depth+1)
+			n += estimateIndirect(iter.Value(), seen, depth+1)
+		}
+		return n
+	case reflect.Struct:
+		n := 0
+		for i := 0; i < rv.NumField(); i++ {
+			n += estimateIndirect(rv.Field(i), seen, depth+1)
+		}
+		return n
+	default:
+		return 0
+	}
+}
+
+// visited records p and reports whether it had been seen before.
+func visited(p uintptr, seen map[uintptr]struct{}) bool {
+	if p == 0 {
+		return false
+	}
+	if _, ok := seen[p]; ok {
+		return true
+	}
+	seen[p] = struct{}{}
+	return false
+}
+
+// defaultItemWeight estimates the memory held by a cache entry.
+func defaultItemWeight(key string, it *Item) int {
+	return EstimateSize(key) + itemOverhead + EstimateSize(it.Value)
+}
+
+// weigher adapts a size function to otter's uint32 weights.
+func weigher(size func(key string, it *Item) int) func(string, *Item) uint32 {
+	return func(key string, it *Item) uint32 {
+		if it == nil {
+			return 0
+		}
+		n := size(key, it)
+		switch {
+		case n <= 0:
+			return 1
+		case int64(n) > math.MaxUint32:
+			return math.MaxUint32
+		}
+		return uint32(n)
+	}
+}
@@ -0,0 +1,122 @@
+package mappo
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time for TTL calculations.
+// Every TTL-bearing type accepts one as an optional config field so expiration
+// can be tested deterministically; leaving it nil uses SystemClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// TickerClock is a Clock that can also drive periodic background work.
+// Clocks that don't implement it fall back to time.NewTicker.
+type TickerClock interface {
+	Clock
+	// NewTicker returns a channel ticking every d and a func to stop it.
+	NewTicker(d time.Duration) (<-chan time.Time, func())
+}
+
+// SystemClock is the Clock backed by time.Now.
+var SystemClock TickerClock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+// clockOrSystem returns c, or SystemClock if c is nil.
+func clockOrSystem(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+	return c
+}
+
+// newTicker starts a ticker on c, falling back to the system ticker.
+func newTicker(c Clock, d time.Duration) (<-chan time.Time, func()) {
+	if tc, ok := c.(TickerClock); ok {
+		return tc.NewTicker(d)
+	}
+	return SystemClock.NewTicker(d)
+}
+
+// FakeClock is a manually advanced Clock for tests.
+// Tickers created from it fire when Advance or Set moves time past their next tick.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers map[*fakeTicker]struct{}
+}
+
+type fakeTicker struct {
+	ch     chan time.Time
+	period time.Duration
+	next   time.Time
+}
+
+// NewFakeClock creates a FakeClock starting at t.
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{now: t, tickers: make(map[*fakeTicker]struct{})}
+}
+
+// Now returns the clock's current time.
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance moves the clock forward by d.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setLocked(f.now.Add(d))
+}
+
+// Set moves the clock to t.
+func (f *FakeClock) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setLocked(t)
+}
+
+func (f *FakeClock) setLocked(t time.Time) {
+	f.now = t
+	for tk := range f.tickers {
+		if t.Before(tk.next) {
+			continue
+		}
+		// Like time.Ticker, drop ticks for slow receivers
+		select {
+		case tk.ch <- t:
+		default:
+		}
+		for !t.Before(tk.next) {
+			tk.next = tk.next.Add(tk.period)
+		}
+	}
+}
+
+// NewTicker implements TickerClock.
+func (f *FakeClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	if d <= 0 {
+		panic("mappo: non-positive interval for FakeClock.NewTicker")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tk := &fakeTicker{ch: make(chan time.Time, 1), period: d, next: f.now.Add(d)}
+	f.tickers[tk] = struct{}{}
+	return tk.ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.tickers, tk)
+	}
+}
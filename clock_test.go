@@ -0,0 +1,96 @@
+package mappo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock_Concurrent(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig[string, int](ConcurrentConfig[string, int]{Clock: clk})
+	c.SetTTL("key", 1, time.Minute)
+
+	clk.Advance(59 * time.Second)
+	if !c.Has("key") {
+		t.Error("expected key alive before TTL")
+	}
+	clk.Advance(2 * time.Second)
+	if c.Has("key") {
+		t.Error("expected key expired after TTL")
+	}
+}
+
+func TestFakeClock_LRU(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	l := NewLRUWithConfig[string, int](LRUConfig[string, int]{
+		MaxSize: 10,
+		TTL:     time.Second,
+		Clock:   clk,
+	})
+	l.Set("key", 1)
+	if _, ok := l.Get("key"); !ok {
+		t.Error("expected key alive")
+	}
+	clk.Advance(2 * time.Second)
+	if _, ok := l.Get("key"); ok {
+		t.Error("expected key expired")
+	}
+}
+
+func TestFakeClock_Cache(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewCache(CacheOptions{MaximumSize: 10, Clock: clk})
+	c.StoreTTL("key", &Item{Value: 1}, time.Second)
+	clk.Advance(2 * time.Second)
+	if c.Has("key") {
+		t.Error("expected key expired")
+	}
+}
+
+func TestFakeClock_Ticker(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	ch, stop := clk.NewTicker(time.Second)
+
+	clk.Advance(500 * time.Millisecond)
+	select {
+	case <-ch:
+		t.Error("expected no tick before period")
+	default:
+	}
+
+	clk.Advance(600 * time.Millisecond)
+	select {
+	case <-ch:
+	default:
+		t.Error("expected tick after period")
+	}
+
+	stop()
+	clk.Advance(5 * time.Second)
+	select {
+	case <-ch:
+		t.Error("expected no tick after stop")
+	default:
+	}
+}
+
+func TestClock_DefaultsToSystem(t *testing.T) {
+	// Configs written before Clock existed leave it nil
+	lru := NewLRUWithConfig[string, int](LRUConfig[string, int]{MaxSize: 10, TTL: time.Minute})
+	sharded := NewShardedWithConfig[string, int](ShardedConfig{ShardCount: 4})
+	clocks := map[string]Clock{
+		"Concurrent": NewConcurrent[string, int]().clock,
+		"LRU":        lru.clock,
+		"Sharded":    sharded.clock,
+	}
+	for name, clk := range clocks {
+		if clk != SystemClock {
+			t.Errorf("%s: expected SystemClock by default, got %T", name, clk)
+		}
+	}
+
+	lru.Set("key", 1)
+	if !lru.Has("key") {
+		t.Error("expected entry alive with the system clock")
+	}
+}
@@ -1,6 +1,13 @@
 package mappo
 
 import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"iter"
+	"math/rand/v2"
+	"sync"
 	"time"
 
 	"github.com/puzpuzpuz/xsync/v3"
@@ -9,57 +16,247 @@ import (
 // Concurrent provides a high-performance concurrent map with optional TTL support.
 // It wraps xsync.MapOf for optimal performance in high-concurrency scenarios.
 type Concurrent[K comparable, V any] struct {
-	m *xsync.MapOf[K, *concurrentEntry[V]]
+	m        *xsync.MapOf[K, concurrentEntry[V]]
+	clock    Clock
+	onExpire func(K, V)
+	equal    func(a, b V) bool
+	// TTL applied by Set and friends, 0 means no expiration
+	defaultTTL time.Duration
+	// Sliding expiration applied on Get, 0 when disabled
+	expireAfterAccess time.Duration
+
+	// Size bound, 0 when unbounded. evictMu serializes victim selection
+	// from pool, the eviction candidates, nil when unbounded.
+	maxSize  int
+	eviction ConcurrentEviction
+	evictMu  sync.Mutex
+	pool     []keyPool[K]
+
+	// Expiration indexes for PurgeExpired, spread to keep TTL writes parallel
+	expiry []expiryIndex[K]
+
+	// Counters behind Stats, nil when EnableStats is not set
+	stats *concurrentCounters
+
+	// In-flight GetOrCompute loaders
+	flights flightGroup[K, V]
+
+	// Janitor lifecycle, nil when CleanupInterval is not set
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
+// concurrentEntry is stored inline in the map, so a write allocates only
+// xsync's own node. Without pointer identity, code racing with writers
+// re-checks expiration instead: an entry that is still expired may be
+// removed no matter who wrote it.
 type concurrentEntry[V any] struct {
 	value      V
 	expiration int64 // UnixNano, 0 means no expiration
 }
 
+// ConcurrentEviction selects which entry a size-bounded Concurrent removes when full.
+type ConcurrentEviction int
+
+const (
+	// EvictRandom removes an arbitrary entry.
+	EvictRandom ConcurrentEviction = iota
+	// EvictOldestExpiration removes the entry closest to expiring among
+	// those sampled. Entries without a TTL lose to any TTL-bearing one.
+	EvictOldestExpiration
+)
+
+// ConcurrentConfig holds configuration for Concurrent map.
+type ConcurrentConfig[K comparable, V any] struct {
+	// Clock is the time source for TTLs. Optional, nil uses SystemClock.
+	Clock Clock
+	// DefaultTTL is applied by Set, SetMany, SetIfAbsent, Swap and the Compute
+	// family when they create an entry. SetTTL(key, v, 0) still stores without
+	// expiration. Zero means entries never expire by default.
+	DefaultTTL time.Duration
+	// CleanupInterval starts a janitor goroutine removing expired entries
+	// at this interval. Call Close to stop it. Zero disables the janitor.
+	CleanupInterval time.Duration
+	// OnExpire is called after an expired entry is removed,
+	// whether by the janitor or lazily on access.
+	OnExpire func(key K, value V)
+	// ExpireAfterAccess extends the TTL of an entry to this duration
+	// when Get returns it. Entries stored without a TTL are unaffected.
+	// To keep reads lock-free the TTL is only re-armed once an eighth of it
+	// has elapsed, so an entry expires between 7/8 and all of this duration
+	// after its last access.
+	ExpireAfterAccess time.Duration
+	// MaxSize bounds the number of entries. When a write exceeds it, expired
+	// entries are dropped first, then Eviction picks a victim. Zero means unbounded.
+	// Like Redis, large maps pick the victim among a few random entries rather
+	// than scanning, so each write at capacity costs O(1).
+	MaxSize int
+	// Eviction selects the victim when MaxSize is exceeded. Defaults to EvictRandom.
+	Eviction ConcurrentEviction
+	// Equal compares values in CompareAndSwap and CompareAndDelete.
+	// Defaults to == for comparable types and reflect.DeepEqual otherwise.
+	Equal func(a, b V) bool
+	// EnableStats turns on the hit, miss, expiration, delete and eviction
+	// counters reported by Stats. Off by default since shared counters add contention to Get.
+	EnableStats bool
+}
+
 // NewConcurrent creates a new concurrent map.
 func NewConcurrent[K comparable, V any]() *Concurrent[K, V] {
-	return &Concurrent[K, V]{
-		m: xsync.NewMapOf[K, *concurrentEntry[V]](),
+	return NewConcurrentWithConfig(ConcurrentConfig[K, V]{})
+}
+
+// NewConcurrentWithConfig creates a new concurrent map with configuration.
+func NewConcurrentWithConfig[K comparable, V any](cfg ConcurrentConfig[K, V]) *Concurrent[K, V] {
+	c := &Concurrent[K, V]{
+		m:        xsync.NewMapOf[K, concurrentEntry[V]](),
+		expiry:   newExpiryIndexes[K](),
+		clock:    clockOrSystem(cfg.Clock),
+		onExpire: cfg.OnExpire,
+		equal:    cfg.Equal,
+
+		defaultTTL:        cfg.DefaultTTL,
+		expireAfterAccess: cfg.ExpireAfterAccess,
+		maxSize:           cfg.MaxSize,
+		eviction:          cfg.Eviction,
+		pool:              newKeyPools[K](cfg.MaxSize),
+	}
+	if c.equal == nil {
+		c.equal = defaultEqual[V]()
+	}
+	if cfg.EnableStats {
+		c.stats = &concurrentCounters{}
+	}
+	if cfg.CleanupInterval > 0 {
+		c.done = make(chan struct{})
+		// Create the ticker before returning so fake clocks see it immediately
+		tick, stop := newTicker(c.clock, cfg.CleanupInterval)
+		go c.janitor(tick, stop)
+	}
+	return c
+}
+
+// janitor periodically removes expired entries until Close is called.
+func (c *Concurrent[K, V]) janitor(tick <-chan time.Time, stop func()) {
+	defer stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-tick:
+			c.PurgeExpired()
+		}
 	}
 }
 
+// Close stops the janitor goroutine, if any. The map remains usable.
+func (c *Concurrent[K, V]) Close() error {
+	if c.done != nil {
+		c.closeOnce.Do(func() { close(c.done) })
+	}
+	return nil
+}
+
 // Get retrieves a value. Returns false if key doesn't exist or is expired.
 func (c *Concurrent[K, V]) Get(key K) (V, bool) {
 	entry, ok := c.m.Load(key)
-	if !ok || entry == nil {
+	if !ok {
+		if c.stats != nil {
+			c.stats.misses.Add(1)
+		}
 		var zero V
 		return zero, false
 	}
 
 	// Check expiration
-	if entry.expiration > 0 && nowNano() > entry.expiration {
-		c.m.Delete(key)
+	now := c.nowNano()
+	if c.expired(entry, now) {
+		c.removeExpired(key)
+		if c.stats != nil {
+			c.stats.misses.Add(1)
+		}
 		var zero V
 		return zero, false
 	}
 
+	if c.stats != nil {
+		c.stats.hits.Add(1)
+	}
+
+	if c.expireAfterAccess > 0 && entry.expiration > 0 {
+		if exp := now + int64(c.expireAfterAccess); exp-entry.expiration > int64(c.expireAfterAccess/slideFraction) {
+			c.slide(key, entry.expiration, exp)
+		}
+	}
+
 	return entry.value, true
 }
 
-// Set stores a value with no expiration.
+// Set stores a value with the default TTL, if any.
 func (c *Concurrent[K, V]) Set(key K, value V) {
-	c.m.Store(key, &concurrentEntry[V]{value: value})
+	c.SetTTL(key, value, c.defaultTTL)
 }
 
 // SetTTL stores a value with TTL.
 func (c *Concurrent[K, V]) SetTTL(key K, value V, ttl time.Duration) {
 	var exp int64
 	if ttl > 0 {
-		exp = time.Now().Add(ttl).UnixNano()
+		exp = c.clock.Now().Add(ttl).UnixNano()
+	}
+	c.store(key, concurrentEntry[V]{value: value, expiration: exp})
+	c.track(key, exp)
+	c.evictIfFull(key)
+}
+
+// GetOrCompute returns the value for key, loading it with fn if missing or expired.
+// Only one fn runs per key at a time; concurrent callers wait for its result.
+// The value is stored with the returned TTL (<= 0 means no expiration) unless fn
+// returns an error, which is then returned to every waiting caller.
+// If fn panics, the panic propagates to its caller and waiters retry the load.
+func (c *Concurrent[K, V]) GetOrCompute(key K, fn func() (V, time.Duration, error)) (V, error) {
+	return c.flights.do(key, c.Get, func() (V, error) {
+		v, ttl, err := fn()
+		if err == nil {
+			c.SetTTL(key, v, ttl)
+		}
+		return v, err
+	})
+}
+
+// GetMany retrieves multiple values in one call.
+// Missing and expired keys are omitted from the result.
+func (c *Concurrent[K, V]) GetMany(keys []K) map[K]V {
+	result := make(map[K]V, len(keys))
+	for _, key := range keys {
+		if v, ok := c.Get(key); ok {
+			result[key] = v
+		}
+	}
+	return result
+}
+
+// SetMany stores multiple values with the default TTL, if any.
+func (c *Concurrent[K, V]) SetMany(items map[K]V) {
+	c.SetManyTTL(items, c.defaultTTL)
+}
+
+// SetManyTTL stores multiple values sharing the same TTL.
+func (c *Concurrent[K, V]) SetManyTTL(items map[K]V, ttl time.Duration) {
+	var exp int64
+	if ttl > 0 {
+		exp = c.clock.Now().Add(ttl).UnixNano()
+	}
+	for key, value := range items {
+		c.store(key, concurrentEntry[V]{value: value, expiration: exp})
+		c.track(key, exp)
+		c.evictIfFull(key)
 	}
-	c.m.Store(key, &concurrentEntry[V]{value: value, expiration: exp})
 }
 
 // SetIfAbsent sets the value only if the key doesn't exist.
 // Returns the actual value and true if loaded (already existed).
 func (c *Concurrent[K, V]) SetIfAbsent(key K, value V) (V, bool) {
-	entry := &concurrentEntry[V]{value: value}
+	entry := concurrentEntry[V]{value: value, expiration: c.defaultExpiration(c.nowNano())}
 
 	actual, loaded := c.m.LoadOrStore(key, entry)
 	if loaded {
@@ -67,6 +264,9 @@ func (c *Concurrent[K, V]) SetIfAbsent(key K, value V) (V, bool) {
 		return actual.value, true
 	}
 	// We stored first: return the value we just stored
+	c.admit(key)
+	c.track(key, entry.expiration)
+	c.evictIfFull(key)
 	return value, false
 }
 
@@ -76,32 +276,95 @@ func (c *Concurrent[K, V]) LoadOrStore(key K, val V) (actual V, loaded bool) {
 	return c.GetOrSet(key, val)
 }
 
+// KeepTTL tells ComputeTTL to preserve the existing entry's expiration.
+// New entries created with KeepTTL get the default TTL, if any.
+const KeepTTL time.Duration = -1
+
 // Compute allows atomic read-modify-write operations.
+// The existing entry's expiration is preserved; use ComputeTTL to change it.
 func (c *Concurrent[K, V]) Compute(key K, fn func(current V, exists bool) (newValue V, keep bool)) V {
-	c.m.Compute(key, func(oldEntry *concurrentEntry[V], exists bool) (*concurrentEntry[V], bool) {
-		var oldV V
-		existsAndValid := exists && oldEntry != nil
+	return c.ComputeTTL(key, func(current V, exists bool) (V, time.Duration, bool) {
+		newV, keep := fn(current, exists)
+		return newV, KeepTTL, keep
+	})
+}
+
+// ComputeTTL is like Compute but lets fn decide the entry's expiration.
+// fn returns KeepTTL to preserve it, a ttl > 0 to set it, or 0 to clear it.
+func (c *Concurrent[K, V]) ComputeTTL(key K, fn func(current V, exists bool) (newValue V, ttl time.Duration, keep bool)) V {
+	v, _ := c.compute(key, func(current V, exists bool) (V, time.Duration, bool, error) {
+		newV, ttl, keep := fn(current, exists)
+		return newV, ttl, keep, nil
+	})
+	return v
+}
 
+// ComputeE is like Compute but lets fn abort with an error.
+// When fn returns an error the entry is left untouched and the error is returned.
+// API matches Sharded.ComputeE
+func (c *Concurrent[K, V]) ComputeE(key K, fn func(current V, exists bool) (newValue V, keep bool, err error)) (V, error) {
+	return c.compute(key, func(current V, exists bool) (V, time.Duration, bool, error) {
+		newV, keep, err := fn(current, exists)
+		return newV, KeepTTL, keep, err
+	})
+}
+
+// compute implements the Compute family. An error from fn leaves the entry untouched.
+func (c *Concurrent[K, V]) compute(key K, fn func(current V, exists bool) (V, time.Duration, bool, error)) (V, error) {
+	var stored concurrentEntry[V]
+	var oldExp int64
+	var written, created, deleted bool
+	var err error
+	c.m.Compute(key, func(oldEntry concurrentEntry[V], exists bool) (concurrentEntry[V], bool) {
+		var oldV V
+		oldExp, written, created, deleted = 0, false, false, false
+		now := c.nowNano()
+		existsAndValid := exists && !c.expired(oldEntry, now)
 		if existsAndValid {
-			if oldEntry.expiration > 0 && nowNano() > oldEntry.expiration {
-				existsAndValid = false
-			} else {
-				oldV = oldEntry.value
-			}
+			oldV, oldExp = oldEntry.value, oldEntry.expiration
 		}
 
-		newV, keep := fn(oldV, existsAndValid)
+		newV, ttl, keep, fnErr := fn(oldV, existsAndValid)
+		if err = fnErr; err != nil {
+			return oldEntry, !exists // leave untouched
+		}
 		if !keep {
-			return nil, true // delete=true: remove the entry
+			deleted = existsAndValid
+			return oldEntry, true // delete=true: remove the entry
 		}
 
-		return &concurrentEntry[V]{value: newV}, false // delete=false: store the entry
+		exp := oldExp
+		switch {
+		case ttl == KeepTTL:
+			if !existsAndValid {
+				exp = c.defaultExpiration(now)
+			}
+		case ttl > 0:
+			exp = now + int64(ttl)
+		default:
+			exp = 0
+		}
+		stored, written, created = concurrentEntry[V]{value: newV, expiration: exp}, true, !exists
+		return stored, false // delete=false: store the entry
 	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	if created {
+		c.admit(key)
+	}
+	if written && stored.expiration != oldExp {
+		c.track(key, stored.expiration)
+	}
+	if deleted {
+		c.countDeletes(1)
+	}
+	c.evictIfFull(key)
 
-	// After Compute, read back the actual stored value
-	// This handles CAS retries correctly
-	val, _ := c.Get(key)
-	return val
+	// Return what fn's final invocation stored, without a second lookup
+	// that would count as a hit or slide the TTL
+	return stored.value, nil
 }
 
 // Delete removes a key.
@@ -109,10 +372,116 @@ func (c *Concurrent[K, V]) Delete(key K) bool {
 	_, existed := c.m.Load(key)
 	if existed {
 		c.m.Delete(key)
+		c.countDeletes(1)
 	}
 	return existed
 }
 
+// DeleteMany removes multiple keys and returns how many existed.
+func (c *Concurrent[K, V]) DeleteMany(keys []K) int {
+	n := 0
+	for _, key := range keys {
+		if _, existed := c.m.LoadAndDelete(key); existed {
+			n++
+		}
+	}
+	c.countDeletes(n)
+	return n
+}
+
+// LoadAndDelete deletes a key and returns its previous value.
+// Returns false if the key didn't exist or was expired.
+func (c *Concurrent[K, V]) LoadAndDelete(key K) (V, bool) {
+	var old V
+	var loaded, expired bool
+	c.m.Compute(key, func(current concurrentEntry[V], exists bool) (concurrentEntry[V], bool) {
+		if exists {
+			old = current.value
+			expired = c.expired(current, c.nowNano())
+			loaded = !expired
+		}
+		return current, true // delete=true
+	})
+	if expired {
+		c.notifyExpired(key, old)
+		var zero V
+		old = zero
+	}
+	if loaded {
+		c.countDeletes(1)
+	}
+	return old, loaded
+}
+
+// Swap stores a value with the default TTL, if any, and returns the previous value.
+// Returns false if the key didn't exist or was expired.
+func (c *Concurrent[K, V]) Swap(key K, value V) (old V, loaded bool) {
+	now := c.nowNano()
+	entry := concurrentEntry[V]{value: value, expiration: c.defaultExpiration(now)}
+	var created bool
+	c.m.Compute(key, func(current concurrentEntry[V], exists bool) (concurrentEntry[V], bool) {
+		if exists && !c.expired(current, now) {
+			old = current.value
+			loaded = true
+		}
+		created = !exists
+		return entry, false // delete=false: store the entry
+	})
+	if created {
+		c.admit(key)
+	}
+	c.track(key, entry.expiration)
+	c.evictIfFull(key)
+	return old, loaded
+}
+
+// RefreshTTL updates the TTL of an existing entry without changing its value.
+// A ttl <= 0 removes the expiration. Returns true if the entry was found and updated.
+func (c *Concurrent[K, V]) RefreshTTL(key K, ttl time.Duration) bool {
+	now := c.nowNano()
+	var exp int64
+	if ttl > 0 {
+		exp = now + int64(ttl)
+	}
+	var refreshed, expired bool
+	var expiredV V
+	c.m.Compute(key, func(current concurrentEntry[V], exists bool) (concurrentEntry[V], bool) {
+		if !exists {
+			return current, true
+		}
+		if c.expired(current, now) {
+			expired, expiredV = true, current.value
+			return current, true // expired, drop it
+		}
+		refreshed = true
+		return concurrentEntry[V]{value: current.value, expiration: exp}, false
+	})
+	if expired {
+		c.notifyExpired(key, expiredV)
+	}
+	if refreshed {
+		c.track(key, exp)
+	}
+	return refreshed
+}
+
+// ExpireAt returns the expiration time of a key.
+// A zero time means the entry never expires. Returns false if the key doesn't exist or is expired.
+func (c *Concurrent[K, V]) ExpireAt(key K) (time.Time, bool) {
+	entry, ok := c.m.Load(key)
+	if !ok {
+		return time.Time{}, false
+	}
+	if c.expired(entry, c.nowNano()) {
+		c.removeExpired(key)
+		return time.Time{}, false
+	}
+	if entry.expiration == 0 {
+		return time.Time{}, true
+	}
+	return time.Unix(0, entry.expiration), true
+}
+
 // Has returns true if the key exists and is not expired.
 func (c *Concurrent[K, V]) Has(key K) bool {
 	_, ok := c.Get(key)
@@ -126,22 +495,49 @@ func (c *Concurrent[K, V]) Len() int {
 
 // Clear removes all items.
 func (c *Concurrent[K, V]) Clear() {
+	c.resetIndex()
 	c.m.Clear()
 }
 
 // Range iterates over all items. Return false to stop.
 // Expired items are skipped and deleted.
 func (c *Concurrent[K, V]) Range(fn func(K, V) bool) {
-	now := nowNano()
-	c.m.Range(func(key K, entry *concurrentEntry[V]) bool {
-		if entry.expiration > 0 && now > entry.expiration {
-			c.m.Delete(key)
+	now := c.nowNano()
+	c.m.Range(func(key K, entry concurrentEntry[V]) bool {
+		if c.expired(entry, now) {
+			c.removeExpired(key)
 			return true
 		}
 		return fn(key, entry.value)
 	})
 }
 
+// All returns an iterator over all non-expired key-value pairs.
+// Expired entries are skipped and deleted, as in Range.
+func (c *Concurrent[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c.Range(yield)
+	}
+}
+
+// KeysSeq returns an iterator over all non-expired keys.
+func (c *Concurrent[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		c.Range(func(k K, _ V) bool {
+			return yield(k)
+		})
+	}
+}
+
+// ValuesSeq returns an iterator over all non-expired values.
+func (c *Concurrent[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		c.Range(func(_ K, v V) bool {
+			return yield(v)
+		})
+	}
+}
+
 // Keys returns all non-expired keys.
 func (c *Concurrent[K, V]) Keys() []K {
 	keys := make([]K, 0, c.Len())
@@ -162,6 +558,45 @@ func (c *Concurrent[K, V]) Values() []V {
 	return values
 }
 
+// RangeCtx is like Range but checks ctx between entries and stops once it is done.
+// Returns ctx.Err() if the scan was cancelled. Expired entries are skipped as in Range.
+// API matches Sharded.RangeCtx
+func (c *Concurrent[K, V]) RangeCtx(ctx context.Context, fn func(K, V) bool) error {
+	return rangeCtx(ctx, c.Range, fn)
+}
+
+// TransformValues atomically rewrites every non-expired entry with fn and
+// returns how many were rewritten. Entries keep their expiration.
+// With parallelism > 1 keys are processed by that many goroutines, so fn must be safe
+// for concurrent use. Keys added during the call may or may not be visited.
+// API matches Sharded.TransformValues
+func (c *Concurrent[K, V]) TransformValues(fn func(K, V) V, parallelism int) int {
+	return transformKeys(c.Keys(), parallelism, func(key K) bool {
+		var rewritten bool
+		c.compute(key, func(current V, exists bool) (V, time.Duration, bool, error) {
+			if !exists {
+				return current, KeepTTL, false, nil // deleted or expired meanwhile
+			}
+			rewritten = true
+			return fn(key, current), KeepTTL, true, nil
+		})
+		return rewritten
+	})
+}
+
+// KeysWithPrefix returns the non-expired keys starting with prefix.
+// Returns nil unless K is a string type.
+// API matches Sharded.KeysWithPrefix
+func (c *Concurrent[K, V]) KeysWithPrefix(prefix string) []K {
+	return keysWithPrefix(c.Range, prefix)
+}
+
+// FindAll returns all non-expired pairs for which pred returns true.
+// API matches Sharded.FindAll
+func (c *Concurrent[K, V]) FindAll(pred func(K, V) bool) map[K]V {
+	return findAll(c.Range, pred)
+}
+
 // Update performs an atomic read-modify-write and returns the new value.
 // Semantically equivalent to Compute(fn) but signals "always keep" intent.
 // API matches Sharded.Update
@@ -171,6 +606,18 @@ func (c *Concurrent[K, V]) Update(key K, fn func(current V, exists bool) V) V {
 	})
 }
 
+// GetAndUpdate atomically applies fn and returns both the previous and the new value.
+// existed reports whether a live entry was present; old is the zero value otherwise.
+// The existing entry's expiration is preserved.
+// API matches Sharded.GetAndUpdate
+func (c *Concurrent[K, V]) GetAndUpdate(key K, fn func(current V, exists bool) V) (old V, updated V, existed bool) {
+	updated, _ = c.compute(key, func(curr V, exists bool) (V, time.Duration, bool, error) {
+		old, existed = curr, exists
+		return fn(curr, exists), KeepTTL, true, nil
+	})
+	return old, updated, existed
+}
+
 // GetOrSet returns the existing value for the key if present, otherwise sets and returns the given value.
 // API matches Sharded.GetOrSet
 func (c *Concurrent[K, V]) GetOrSet(key K, val V) (actual V, loaded bool) {
@@ -187,16 +634,18 @@ func (c *Concurrent[K, V]) Size() int {
 // API matches Sharded.ClearIf
 func (c *Concurrent[K, V]) ClearIf(shouldRemove func(K, V) bool) int {
 	var total int
-	c.m.Range(func(key K, entry *concurrentEntry[V]) bool {
+	c.m.Range(func(key K, entry concurrentEntry[V]) bool {
 		// Check expiration first
-		if entry.expiration > 0 && nowNano() > entry.expiration {
-			c.m.Delete(key)
-			total++
+		if c.expired(entry, c.nowNano()) {
+			if c.removeExpired(key) {
+				total++
+			}
 			return true
 		}
 
 		if shouldRemove(key, entry.value) {
 			c.m.Delete(key)
+			c.countDeletes(1)
 			total++
 		}
 		return true
@@ -205,57 +654,335 @@ func (c *Concurrent[K, V]) ClearIf(shouldRemove func(K, V) bool) int {
 }
 
 // Replace replaces the value for a key only if it exists.
+// The entry keeps its expiration; expired keys are not replaced.
 // Returns the old value and true if replaced.
 // API matches Sharded.Replace
 func (c *Concurrent[K, V]) Replace(key K, val V) (V, bool) {
 	var old V
 	var replaced bool
 
-	c.m.Compute(key, func(current *concurrentEntry[V], exists bool) (*concurrentEntry[V], bool) {
-		if !exists || current == nil {
-			return nil, false // don't create
-		}
-		// Check expiration
-		if current.expiration > 0 && nowNano() > current.expiration {
-			return nil, false // expired, don't create
+	c.m.Compute(key, func(current concurrentEntry[V], exists bool) (concurrentEntry[V], bool) {
+		if !exists || c.expired(current, c.nowNano()) {
+			return current, !exists // don't create, leave expired entries to lazy removal
 		}
 		old = current.value
 		replaced = true
-		return &concurrentEntry[V]{value: val, expiration: current.expiration}, true
+		return concurrentEntry[V]{value: val, expiration: current.expiration}, false
 	})
 
 	return old, replaced
 }
 
 // CompareAndSwap swaps the value if the current value matches old.
+// Values are compared with ConcurrentConfig.Equal, see CompareAndDelete for the default.
 // API matches Sharded.CompareAndSwap
 func (c *Concurrent[K, V]) CompareAndSwap(key K, old V, newV V) bool {
-	var swapped bool
-	c.m.Compute(key, func(current *concurrentEntry[V], exists bool) (*concurrentEntry[V], bool) {
-		if !exists || current == nil {
-			swapped = false
-			return nil, false
-		}
+	eq := c.equal
+	return c.CompareAndSwapFunc(key, func(current V) bool {
+		return eq(current, old)
+	}, newV)
+}
 
-		// Check expiration
-		if current.expiration > 0 && nowNano() > current.expiration {
-			swapped = false
-			return nil, false
+// CompareAndSwapFunc swaps the value only if match returns true for the current value.
+// The entry keeps its expiration.
+func (c *Concurrent[K, V]) CompareAndSwapFunc(key K, match func(current V) bool, newV V) bool {
+	var swapped, expired bool
+	var expiredV V
+	c.m.Compute(key, func(current concurrentEntry[V], exists bool) (concurrentEntry[V], bool) {
+		if !exists {
+			return current, true // don't create
 		}
+		if c.expired(current, c.nowNano()) {
+			expired, expiredV = true, current.value
+			return current, true // expired, drop it
+		}
+		if !match(current.value) {
+			return current, false // keep
+		}
+		swapped = true
+		return concurrentEntry[V]{value: newV, expiration: current.expiration}, false
+	})
+	if expired {
+		c.notifyExpired(key, expiredV)
+	}
+	return swapped
+}
 
-		// Use reflection for generic comparison
-		if any(current.value) != any(old) {
-			swapped = false
+// CompareAndDelete deletes the key only if its current value equals old.
+// Values are compared with ConcurrentConfig.Equal, which defaults to == for
+// comparable types and reflect.DeepEqual otherwise.
+// API matches Sharded.CompareAndDelete
+func (c *Concurrent[K, V]) CompareAndDelete(key K, old V) bool {
+	eq := c.equal
+	return c.CompareAndDeleteFunc(key, func(current V) bool {
+		return eq(current, old)
+	})
+}
+
+// CompareAndDeleteFunc deletes the key only if match returns true for its current value.
+// API matches Sharded.CompareAndDeleteFunc
+func (c *Concurrent[K, V]) CompareAndDeleteFunc(key K, match func(current V) bool) bool {
+	var deleted, expired bool
+	var expiredV V
+	c.m.Compute(key, func(current concurrentEntry[V], exists bool) (concurrentEntry[V], bool) {
+		if !exists {
 			return current, true
 		}
+		if c.expired(current, c.nowNano()) {
+			expired, expiredV = true, current.value
+			return current, true // expired, drop it
+		}
+		if !match(current.value) {
+			return current, false // keep
+		}
+		deleted = true
+		return current, true
+	})
+	if expired {
+		c.notifyExpired(key, expiredV)
+	}
+	if deleted {
+		c.countDeletes(1)
+	}
+	return deleted
+}
 
-		swapped = true
-		return &concurrentEntry[V]{value: newV, expiration: current.expiration}, true
+// concurrentJSONEntry is the wire form of one Concurrent entry.
+// A list is used instead of an object so keys need not be strings.
+type concurrentJSONEntry[K comparable, V any] struct {
+	Key   K             `json:"key"`
+	Value V             `json:"value"`
+	TTL   time.Duration `json:"ttl,omitempty"` // remaining, 0 means no expiration
+}
+
+// MarshalJSON implements json.Marshaler.
+// Only non-expired entries are written, each with its remaining TTL.
+func (c *Concurrent[K, V]) MarshalJSON() ([]byte, error) {
+	now := c.nowNano()
+	entries := make([]concurrentJSONEntry[K, V], 0, c.Len())
+	c.m.Range(func(key K, entry concurrentEntry[V]) bool {
+		if c.expired(entry, now) {
+			return true
+		}
+		e := concurrentJSONEntry[K, V]{Key: key, Value: entry.value}
+		if entry.expiration > 0 {
+			e.TTL = time.Duration(entry.expiration - now)
+		}
+		entries = append(entries, e)
+		return true
 	})
-	return swapped
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// Entries are merged into the map with their remaining TTL counted from now,
+// so time spent serialized does not count against them.
+// A zero Concurrent is initialized with default configuration.
+func (c *Concurrent[K, V]) UnmarshalJSON(b []byte) error {
+	var entries []concurrentJSONEntry[K, V]
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+	c.initZero()
+	for _, e := range entries {
+		c.SetTTL(e.Key, e.Value, e.TTL)
+	}
+	return nil
+}
+
+// concurrentGobEntry is the gob form of one Concurrent entry.
+type concurrentGobEntry[K comparable, V any] struct {
+	Key        K
+	Value      V
+	Expiration int64 // UnixNano, 0 means no expiration
+}
+
+// SaveTo writes all non-expired entries with their absolute expirations to w using gob.
+// Interface-typed values must have their concrete types registered with gob.Register.
+func (c *Concurrent[K, V]) SaveTo(w io.Writer) error {
+	now := c.nowNano()
+	entries := make([]concurrentGobEntry[K, V], 0, c.Len())
+	c.m.Range(func(key K, entry concurrentEntry[V]) bool {
+		if !c.expired(entry, now) {
+			entries = append(entries, concurrentGobEntry[K, V]{Key: key, Value: entry.value, Expiration: entry.expiration})
+		}
+		return true
+	})
+	return gob.NewEncoder(w).Encode(entries)
+}
+
+// LoadFrom merges entries written by SaveTo into the map.
+// Expirations are absolute, so entries that expired in the meantime are skipped.
+// A zero Concurrent is initialized with default configuration.
+func (c *Concurrent[K, V]) LoadFrom(r io.Reader) error {
+	var entries []concurrentGobEntry[K, V]
+	if err := gob.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+	c.initZero()
+	now := c.nowNano()
+	for _, e := range entries {
+		entry := concurrentEntry[V]{value: e.Value, expiration: e.Expiration}
+		if c.expired(entry, now) {
+			continue
+		}
+		c.store(e.Key, entry)
+		c.track(e.Key, e.Expiration)
+		c.evictIfFull(e.Key)
+	}
+	return nil
+}
+
+// initZero makes a zero Concurrent usable, as if created by NewConcurrent.
+func (c *Concurrent[K, V]) initZero() {
+	if c.m == nil {
+		c.m = xsync.NewMapOf[K, concurrentEntry[V]]()
+		c.expiry = newExpiryIndexes[K]()
+		c.clock = SystemClock
+		c.equal = defaultEqual[V]()
+	}
+}
+
+// expired reports whether the entry's TTL has passed at now.
+func (c *Concurrent[K, V]) expired(e concurrentEntry[V], now int64) bool {
+	return e.expiration > 0 && now > e.expiration
+}
+
+// removeExpired deletes key only if its entry is still expired, so a value
+// stored concurrently is never lost. Returns true if the entry was removed.
+func (c *Concurrent[K, V]) removeExpired(key K) bool {
+	removed := false
+	var value V
+	c.m.Compute(key, func(current concurrentEntry[V], exists bool) (concurrentEntry[V], bool) {
+		if !exists || !c.expired(current, c.nowNano()) {
+			return current, !exists // leave untouched
+		}
+		removed, value = true, current.value
+		return current, true
+	})
+	if removed {
+		c.notifyExpired(key, value)
+	}
+	return removed
+}
+
+// slideFraction is the share of ExpireAfterAccess, as a divisor, that must
+// elapse before Get re-arms an entry's TTL.
+const slideFraction = 8
+
+// slide pushes the expiration of key from seen to exp, unless the entry was
+// rewritten with another expiration concurrently or already expires later.
+func (c *Concurrent[K, V]) slide(key K, seen, exp int64) {
+	slid := false
+	c.m.Compute(key, func(current concurrentEntry[V], exists bool) (concurrentEntry[V], bool) {
+		if !exists || current.expiration != seen || current.expiration >= exp {
+			return current, !exists // leave untouched
+		}
+		slid = true
+		return concurrentEntry[V]{value: current.value, expiration: exp}, false
+	})
+	if slid {
+		c.track(key, exp)
+	}
+}
+
+// notifyExpired counts an expired entry and invokes OnExpire, if set.
+func (c *Concurrent[K, V]) notifyExpired(key K, value V) {
+	if c.stats != nil {
+		c.stats.expirations.Add(1)
+	}
+	if c.onExpire != nil {
+		c.onExpire(key, value)
+	}
+}
+
+// countDeletes records n explicit deletions when stats are enabled.
+func (c *Concurrent[K, V]) countDeletes(n int) {
+	if c.stats != nil {
+		c.stats.deletes.Add(int64(n))
+	}
+}
+
+// evictIfFull removes entries until the map fits MaxSize.
+// The just-written key is never chosen as the victim.
+func (c *Concurrent[K, V]) evictIfFull(written K) {
+	if c.maxSize <= 0 || c.m.Size() <= c.maxSize {
+		return
+	}
+	c.evictMu.Lock()
+	defer c.evictMu.Unlock()
+	for c.m.Size() > c.maxSize {
+		if c.PurgeExpired() > 0 {
+			continue
+		}
+		key, exp, ok := c.victim(written)
+		if !ok {
+			return
+		}
+		c.m.Compute(key, func(current concurrentEntry[V], exists bool) (concurrentEntry[V], bool) {
+			if !exists || current.expiration != exp {
+				return current, !exists // rewritten concurrently, pick again
+			}
+			if c.stats != nil {
+				c.stats.evictions.Add(1)
+			}
+			return current, true
+		})
+	}
+}
+
+// victim picks the key to evict according to the eviction policy
+// and returns it with the expiration it had when picked. Small maps are
+// scanned in full; larger ones are sampled.
+func (c *Concurrent[K, V]) victim(skip K) (K, int64, bool) {
+	if c.m.Size() > 2*evictionSamples {
+		if key, exp, ok := c.sampleVictim(skip); ok {
+			return key, exp, true
+		}
+	}
+	var (
+		key   K
+		exp   int64
+		found bool
+		seen  int
+	)
+	c.m.Range(func(k K, e concurrentEntry[V]) bool {
+		if k == skip {
+			return true
+		}
+		seen++
+		switch {
+		case !found:
+		case c.eviction == EvictOldestExpiration:
+			if !expiresBefore(e.expiration, exp) {
+				return true
+			}
+		case rand.IntN(seen) != 0: // reservoir sampling keeps each entry with equal chance
+			return true
+		}
+		key, exp, found = k, e.expiration, true
+		return true
+	})
+	return key, exp, found
+}
+
+// expiresBefore reports whether expiration a comes before b, treating 0 as never.
+func expiresBefore(a, b int64) bool {
+	if a == 0 {
+		return false
+	}
+	return b == 0 || a < b
+}
+
+// defaultExpiration returns the expiration for entries stored with the default TTL.
+func (c *Concurrent[K, V]) defaultExpiration(now int64) int64 {
+	if c.defaultTTL <= 0 {
+		return 0
+	}
+	return now + int64(c.defaultTTL)
 }
 
 // nowNano returns current time in nanoseconds.
-func nowNano() int64 {
-	return time.Now().UnixNano()
+func (c *Concurrent[K, V]) nowNano() int64 {
+	return c.clock.Now().UnixNano()
 }
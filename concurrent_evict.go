@@ -0,0 +1,119 @@
+package mappo
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// evictionSamples is how many live entries victim compares in a large map,
+// as Redis does for its approximated LRU and TTL policies.
+const evictionSamples = 5
+
+// keyPool holds the keys of a size-bounded Concurrent so victims can be
+// sampled at random instead of found by scanning the map. Keys are appended
+// when a write creates them and dropped lazily once found deleted, like items
+// of the expiry index. A Concurrent spreads keys over several pools so
+// creating writes don't serialize on one mutex.
+type keyPool[K comparable] struct {
+	mu   sync.Mutex
+	keys []K
+	_    padding
+}
+
+// newKeyPools returns one pool per P for a bounded map, nil otherwise.
+func newKeyPools[K comparable](maxSize int) []keyPool[K] {
+	if maxSize <= 0 {
+		return nil
+	}
+	return make([]keyPool[K], perPCount())
+}
+
+// store writes entry and makes key an eviction candidate if the write created it.
+func (c *Concurrent[K, V]) store(key K, entry concurrentEntry[V]) {
+	if c.pool == nil {
+		c.m.Store(key, entry)
+		return
+	}
+	if _, loaded := c.m.LoadAndStore(key, entry); !loaded {
+		c.admit(key)
+	}
+}
+
+// admit makes a newly created key an eviction candidate. No-op when unbounded.
+func (c *Concurrent[K, V]) admit(key K) {
+	if c.pool == nil {
+		return
+	}
+	p := &c.pool[rand.Uint32()&uint32(len(c.pool)-1)]
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, key)
+	// Deletes leave stale keys behind; rebuild once they dominate
+	if n := len(p.keys); n >= minIndexCompact && n > 2*(c.m.Size()/len(c.pool)+1) {
+		c.compactPool(p)
+	}
+}
+
+// compactPool drops keys no longer in the map, and duplicates.
+// Callers must hold p.mu.
+func (c *Concurrent[K, V]) compactPool(p *keyPool[K]) {
+	keys := p.keys[:0]
+	seen := make(map[K]struct{}, len(p.keys))
+	for _, k := range p.keys {
+		if _, dup := seen[k]; dup {
+			continue
+		}
+		if _, ok := c.m.Load(k); ok {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+	clear(p.keys[len(keys):])
+	p.keys = keys
+}
+
+// sampleVictim picks the victim among a few random live entries: the first
+// one for EvictRandom, the one expiring soonest for EvictOldestExpiration.
+func (c *Concurrent[K, V]) sampleVictim(skip K) (K, int64, bool) {
+	var (
+		key   K
+		exp   int64
+		found bool
+	)
+	start := rand.IntN(len(c.pool))
+	for i, samples := 0, 0; samples < evictionSamples && i < 2*evictionSamples*len(c.pool); i++ {
+		k, e, ok := c.samplePool(&c.pool[(start+i)%len(c.pool)])
+		if !ok || k == skip {
+			continue
+		}
+		samples++
+		if !found || c.eviction == EvictOldestExpiration && expiresBefore(e.expiration, exp) {
+			key, exp, found = k, e.expiration, true
+		}
+		if c.eviction == EvictRandom {
+			break
+		}
+	}
+	return key, exp, found
+}
+
+// samplePool returns a random key of p that is still in the map with its
+// entry, dropping the stale keys it comes across.
+func (c *Concurrent[K, V]) samplePool(p *keyPool[K]) (K, concurrentEntry[V], bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.keys) > 0 {
+		i := rand.IntN(len(p.keys))
+		k := p.keys[i]
+		if e, ok := c.m.Load(k); ok {
+			return k, e, true
+		}
+		last := len(p.keys) - 1
+		p.keys[i] = p.keys[last]
+		var zero K
+		p.keys[last] = zero
+		p.keys = p.keys[:last]
+	}
+	var zero K
+	return zero, concurrentEntry[V]{}, false
+}
@@ -0,0 +1,126 @@
+package mappo
+
+import (
+	"container/heap"
+	"math/bits"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+)
+
+// minIndexCompact is the heap size below which stale items are never compacted.
+const minIndexCompact = 64
+
+// expiryIndex is a min-heap of keys ordered by expiration.
+// Items are never updated in place: every TTL write pushes a new item and
+// items whose expiration no longer matches the live entry are dropped lazily.
+// A Concurrent spreads writes over several indexes so TTL writers don't
+// serialize on one mutex; since stale items are tolerated anyway, an item may
+// go to any of them.
+type expiryIndex[K comparable] struct {
+	mu sync.Mutex
+	h  expiryHeap[K]
+	_  padding
+}
+
+// newExpiryIndexes returns one index per P.
+func newExpiryIndexes[K comparable]() []expiryIndex[K] {
+	return make([]expiryIndex[K], perPCount())
+}
+
+// perPCount returns GOMAXPROCS rounded up to a power of two.
+func perPCount() int {
+	return 1 << bits.Len(uint(runtime.GOMAXPROCS(0)-1))
+}
+
+type expiryItem[K comparable] struct {
+	key        K
+	expiration int64
+}
+
+// expiryHeap implements heap.Interface ordered by expiration.
+type expiryHeap[K comparable] []expiryItem[K]
+
+func (h expiryHeap[K]) Len() int           { return len(h) }
+func (h expiryHeap[K]) Less(i, j int) bool { return h[i].expiration < h[j].expiration }
+func (h expiryHeap[K]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap[K]) Push(x any)        { *h = append(*h, x.(expiryItem[K])) }
+func (h *expiryHeap[K]) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// track records that key expires at expiration. Zero expirations are ignored.
+func (c *Concurrent[K, V]) track(key K, expiration int64) {
+	if expiration == 0 {
+		return
+	}
+	idx := &c.expiry[rand.Uint32()&uint32(len(c.expiry)-1)]
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	heap.Push(&idx.h, expiryItem[K]{key: key, expiration: expiration})
+	// Overwrites leave stale items behind; rebuild once they dominate
+	if n := len(idx.h); n >= minIndexCompact && n > 2*(c.m.Size()/len(c.expiry)+1) {
+		c.compactIndex(idx)
+	}
+}
+
+// compactIndex drops items that no longer match a live entry, and duplicates.
+// Callers must hold idx.mu.
+func (c *Concurrent[K, V]) compactIndex(idx *expiryIndex[K]) {
+	h := idx.h[:0]
+	seen := make(map[K]struct{}, len(idx.h))
+	for _, it := range idx.h {
+		if _, dup := seen[it.key]; dup {
+			continue
+		}
+		if e, ok := c.m.Load(it.key); ok && e.expiration == it.expiration {
+			seen[it.key] = struct{}{}
+			h = append(h, it)
+		}
+	}
+	clear(idx.h[len(h):])
+	idx.h = h
+	heap.Init(&idx.h)
+}
+
+// resetIndex discards all tracked expirations.
+func (c *Concurrent[K, V]) resetIndex() {
+	for i := range c.expiry {
+		idx := &c.expiry[i]
+		idx.mu.Lock()
+		idx.h = nil
+		idx.mu.Unlock()
+	}
+}
+
+// PurgeExpired removes all expired entries and returns how many were removed.
+// It walks the expiration index, so the cost is proportional to the number of
+// expired entries rather than the size of the map.
+func (c *Concurrent[K, V]) PurgeExpired() int {
+	now := c.nowNano()
+	var due []expiryItem[K]
+	for i := range c.expiry {
+		idx := &c.expiry[i]
+		idx.mu.Lock()
+		for len(idx.h) > 0 && now > idx.h[0].expiration {
+			due = append(due, heap.Pop(&idx.h).(expiryItem[K]))
+		}
+		idx.mu.Unlock()
+	}
+
+	removed := 0
+	for _, it := range due {
+		entry, ok := c.m.Load(it.key)
+		if !ok || entry.expiration != it.expiration {
+			continue // stale: overwritten, refreshed or deleted
+		}
+		if c.removeExpired(it.key) {
+			removed++
+		}
+	}
+	return removed
+}
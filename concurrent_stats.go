@@ -0,0 +1,45 @@
+package mappo
+
+import "sync/atomic"
+
+// ConcurrentStats is a snapshot of Concurrent counters.
+// Counters stay zero unless ConcurrentConfig.EnableStats is set.
+type ConcurrentStats struct {
+	Hits        int64
+	Misses      int64
+	Expirations int64 // Removed after their TTL passed
+	Deletes     int64 // Removed explicitly via Delete, ClearIf, etc.
+	Evictions   int64 // Removed to respect MaxSize
+	Size        int64
+}
+
+// HitRatio returns the fraction of Get calls that found a live entry.
+func (s ConcurrentStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// concurrentCounters holds the live counters behind ConcurrentStats.
+type concurrentCounters struct {
+	hits        atomic.Int64
+	misses      atomic.Int64
+	expirations atomic.Int64
+	deletes     atomic.Int64
+	evictions   atomic.Int64
+}
+
+// Stats returns a snapshot of the map's counters.
+func (c *Concurrent[K, V]) Stats() ConcurrentStats {
+	s := ConcurrentStats{Size: int64(c.Len())}
+	if c.stats != nil {
+		s.Hits = c.stats.hits.Load()
+		s.Misses = c.stats.misses.Load()
+		s.Expirations = c.stats.expirations.Load()
+		s.Deletes = c.stats.deletes.Load()
+		s.Evictions = c.stats.evictions.Load()
+	}
+	return s
+}
@@ -1,8 +1,14 @@
 package mappo
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -86,6 +92,48 @@ func TestConcurrent_Compute(t *testing.T) {
 	}
 }
 
+func TestConcurrent_ComputeTTL(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig(ConcurrentConfig[string, int]{Clock: clk})
+	c.SetTTL("hits", 1, 10*time.Second)
+
+	c.Compute("hits", func(current int, _ bool) (int, bool) {
+		return current + 1, true
+	})
+	if exp, _ := c.ExpireAt("hits"); !exp.Equal(time.Unix(10, 0)) {
+		t.Errorf("Compute should preserve the TTL, got %v", exp)
+	}
+
+	v := c.ComputeTTL("hits", func(current int, _ bool) (int, time.Duration, bool) {
+		return current + 1, time.Minute, true
+	})
+	if exp, _ := c.ExpireAt("hits"); v != 3 || !exp.Equal(time.Unix(60, 0)) {
+		t.Errorf("ComputeTTL should set the TTL, got %d %v", v, exp)
+	}
+
+	c.ComputeTTL("hits", func(current int, _ bool) (int, time.Duration, bool) {
+		return current, 0, true
+	})
+	if exp, ok := c.ExpireAt("hits"); !ok || !exp.IsZero() {
+		t.Errorf("ComputeTTL(0) should clear the TTL, got %v", exp)
+	}
+
+	c.ComputeTTL("new", func(_ int, exists bool) (int, time.Duration, bool) {
+		return 1, KeepTTL, true
+	})
+	if exp, ok := c.ExpireAt("new"); !ok || !exp.IsZero() {
+		t.Errorf("KeepTTL on a new key should not expire, got %v", exp)
+	}
+
+	c.ComputeTTL("short", func(int, bool) (int, time.Duration, bool) {
+		return 1, time.Second, true
+	})
+	clk.Advance(2 * time.Second)
+	if n := c.PurgeExpired(); n != 1 {
+		t.Errorf("ComputeTTL entries should be indexed for purging, got %d", n)
+	}
+}
+
 func TestConcurrent_ConcurrentAccess(t *testing.T) {
 	c := NewConcurrent[int, int]()
 	const numGoroutines = 100
@@ -226,6 +274,728 @@ func TestConcurrent_APIMatch(t *testing.T) {
 	}
 }
 
+func TestConcurrent_LoadAndDelete(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig[string, int](ConcurrentConfig[string, int]{Clock: clk})
+
+	c.Set("key", 1)
+	val, ok := c.LoadAndDelete("key")
+	if !ok || val != 1 {
+		t.Errorf("Expected 1, got %d, ok=%v", val, ok)
+	}
+	if c.Has("key") {
+		t.Error("Key should be deleted")
+	}
+
+	c.SetTTL("ttl", 2, time.Second)
+	clk.Advance(2 * time.Second)
+	if _, ok := c.LoadAndDelete("ttl"); ok {
+		t.Error("Expired key should not be loaded")
+	}
+	if c.Len() != 0 {
+		t.Error("Expired key should be removed")
+	}
+}
+
+func TestConcurrent_Swap(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig[string, int](ConcurrentConfig[string, int]{Clock: clk})
+
+	if _, loaded := c.Swap("key", 1); loaded {
+		t.Error("First Swap should not load")
+	}
+	old, loaded := c.Swap("key", 2)
+	if !loaded || old != 1 {
+		t.Errorf("Expected old 1, got %d, loaded=%v", old, loaded)
+	}
+
+	c.SetTTL("ttl", 3, time.Second)
+	clk.Advance(2 * time.Second)
+	if _, loaded := c.Swap("ttl", 4); loaded {
+		t.Error("Swap over expired key should not load")
+	}
+	if val, ok := c.Get("ttl"); !ok || val != 4 {
+		t.Errorf("Expected 4 after swap, got %d", val)
+	}
+}
+
+func TestConcurrent_Replace(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig[string, int](ConcurrentConfig[string, int]{Clock: clk})
+
+	if _, replaced := c.Replace("missing", 1); replaced || c.Has("missing") {
+		t.Error("Replace should not create missing keys")
+	}
+
+	c.SetTTL("key", 1, 10*time.Second)
+	old, replaced := c.Replace("key", 2)
+	if !replaced || old != 1 {
+		t.Errorf("Expected old 1, got %d, replaced=%v", old, replaced)
+	}
+	// The replaced entry stays in the map with its expiration
+	if val, ok := c.Get("key"); !ok || val != 2 {
+		t.Errorf("Expected 2 after Replace, got %d, ok=%v", val, ok)
+	}
+	if exp, _ := c.ExpireAt("key"); !exp.Equal(time.Unix(10, 0)) {
+		t.Errorf("Replace should preserve the TTL, got %v", exp)
+	}
+
+	clk.Advance(11 * time.Second)
+	if _, replaced := c.Replace("key", 3); replaced {
+		t.Error("Replace should not revive expired keys")
+	}
+}
+
+func TestConcurrent_CompareAndDelete(t *testing.T) {
+	c := NewConcurrent[string, int]()
+	c.Set("key", 1)
+
+	if c.CompareAndDelete("key", 2) {
+		t.Error("Should not delete on mismatch")
+	}
+	if !c.CompareAndDelete("key", 1) {
+		t.Error("Should delete on match")
+	}
+	if c.Has("key") {
+		t.Error("Key should be gone")
+	}
+
+	s := NewConcurrent[string, []int]()
+	s.Set("key", []int{1, 2})
+	if !s.CompareAndDelete("key", []int{1, 2}) {
+		t.Error("Non-comparable values should use deep equality")
+	}
+
+	c.Set("key", 10)
+	if !c.CompareAndDeleteFunc("key", func(v int) bool { return v > 5 }) {
+		t.Error("CompareAndDeleteFunc should delete on predicate match")
+	}
+}
+
+func TestConcurrent_CompareAndSwap(t *testing.T) {
+	c := NewConcurrent[string, []int]()
+	c.Set("key", []int{1, 2})
+	if !c.CompareAndSwap("key", []int{1, 2}, []int{3}) {
+		t.Error("Non-comparable values should use deep equality")
+	}
+	if v, _ := c.Get("key"); !slices.Equal(v, []int{3}) {
+		t.Errorf("Expected swapped value, got %v", v)
+	}
+	if c.CompareAndSwap("key", []int{1, 2}, []int{4}) || !c.Has("key") {
+		t.Error("Mismatched CompareAndSwap should keep the entry")
+	}
+	if c.CompareAndSwap("missing", nil, []int{1}) || c.Len() != 1 {
+		t.Error("CompareAndSwap should not create missing keys")
+	}
+
+	type user struct{ ID, Version int }
+	byID := NewConcurrentWithConfig(ConcurrentConfig[string, *user]{
+		Equal: func(a, b *user) bool { return a.ID == b.ID },
+	})
+	byID.Set("u", &user{ID: 1})
+	if !byID.CompareAndSwap("u", &user{ID: 1}, &user{ID: 1, Version: 2}) {
+		t.Error("Custom Equal should be used")
+	}
+	if !byID.CompareAndSwapFunc("u", func(u *user) bool { return u.Version == 2 }, &user{ID: 1, Version: 3}) {
+		t.Error("CompareAndSwapFunc should swap on predicate match")
+	}
+	if u, _ := byID.Get("u"); u.Version != 3 {
+		t.Errorf("Expected version 3, got %d", u.Version)
+	}
+}
+
+func TestConcurrent_Scans(t *testing.T) {
+	type id string
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig(ConcurrentConfig[id, int]{Clock: clk})
+	c.Set("user:1", 1)
+	c.SetTTL("user:2", 2, time.Second)
+	c.Set("order:1", 3)
+	clk.Advance(2 * time.Second)
+
+	if keys := c.KeysWithPrefix("user:"); !slices.Equal(keys, []id{"user:1"}) {
+		t.Errorf("Expected only live user keys, got %v", keys)
+	}
+	found := c.FindAll(func(k id, v int) bool { return v > 0 })
+	if len(found) != 2 || found["order:1"] != 3 {
+		t.Errorf("Unexpected matches %v", found)
+	}
+}
+
+func TestConcurrent_Stats(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig(ConcurrentConfig[string, int]{
+		Clock:       clk,
+		EnableStats: true,
+		MaxSize:     3,
+	})
+	c.Set("a", 1)
+	c.SetTTL("b", 2, time.Second)
+	c.Get("a")
+	c.Get("missing")
+	clk.Advance(2 * time.Second)
+	c.Get("b")
+	c.Delete("a")
+	for i := range 4 {
+		c.Set(fmt.Sprint(i), i)
+	}
+
+	s := c.Stats()
+	want := ConcurrentStats{Hits: 1, Misses: 2, Expirations: 1, Deletes: 1, Evictions: 1, Size: 3}
+	if s != want {
+		t.Errorf("Expected %+v, got %+v", want, s)
+	}
+	if r := s.HitRatio(); r < 0.33 || r > 0.34 {
+		t.Errorf("Expected hit ratio 1/3, got %f", r)
+	}
+
+	if s := NewConcurrent[string, int]().Stats(); s.Hits != 0 || s.Misses != 0 {
+		t.Error("Stats should stay zero when disabled")
+	}
+}
+
+func TestConcurrent_ComputeE(t *testing.T) {
+	m := NewConcurrent[string, int]()
+	m.Set("balance", 10)
+	errInsufficient := errors.New("insufficient funds")
+
+	withdraw := func(amount int) (int, error) {
+		return m.ComputeE("balance", func(current int, exists bool) (int, bool, error) {
+			if current < amount {
+				return 0, false, errInsufficient
+			}
+			return current - amount, true, nil
+		})
+	}
+	if v, err := withdraw(4); err != nil || v != 6 {
+		t.Errorf("Expected 6, got %d %v", v, err)
+	}
+	if _, err := withdraw(100); !errors.Is(err, errInsufficient) {
+		t.Errorf("Expected error, got %v", err)
+	}
+	if v, ok := m.Get("balance"); !ok || v != 6 {
+		t.Errorf("Failed ComputeE should leave the entry untouched, got %d %v", v, ok)
+	}
+
+	if _, err := m.ComputeE("missing", func(int, bool) (int, bool, error) {
+		return 0, false, errInsufficient
+	}); err == nil || m.Has("missing") {
+		t.Error("Failed ComputeE should not create the key")
+	}
+}
+
+func TestConcurrent_RangeCtx(t *testing.T) {
+	m := NewConcurrent[int, int]()
+	for i := range 100 {
+		m.Set(i, i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	seen := 0
+	err := m.RangeCtx(ctx, func(int, int) bool {
+		seen++
+		if seen == 10 {
+			cancel()
+		}
+		return true
+	})
+	if !errors.Is(err, context.Canceled) || seen != 10 {
+		t.Errorf("Expected cancellation after 10 entries, got %d %v", seen, err)
+	}
+
+	seen = 0
+	if err := m.RangeCtx(context.Background(), func(int, int) bool {
+		seen++
+		return true
+	}); err != nil || seen != 100 {
+		t.Errorf("Expected full scan, got %d %v", seen, err)
+	}
+}
+
+func TestConcurrent_GetAndUpdate(t *testing.T) {
+	m := NewConcurrent[string, int]()
+	add := func(curr int, _ bool) int { return curr + 5 }
+
+	if old, cur, existed := m.GetAndUpdate("gauge", add); old != 0 || cur != 5 || existed {
+		t.Errorf("Expected 0/5/false, got %d/%d/%v", old, cur, existed)
+	}
+	if old, cur, existed := m.GetAndUpdate("gauge", add); old != 5 || cur != 10 || !existed {
+		t.Errorf("Expected 5/10/true, got %d/%d/%v", old, cur, existed)
+	}
+}
+
+func TestConcurrent_WriteAllocs(t *testing.T) {
+	c := NewConcurrent[int, int]()
+	c.Set(1, 1)
+	allocs := testing.AllocsPerRun(100, func() {
+		c.Set(1, 2)
+	})
+	// Only xsync's own node is allocated, the entry is stored inline
+	if allocs > 1 {
+		t.Errorf("Expected at most 1 allocation per Set, got %.1f", allocs)
+	}
+}
+
+func TestConcurrent_TransformValues(t *testing.T) {
+	m := NewConcurrent[int, int]()
+	for i := range 1000 {
+		m.Set(i, i)
+	}
+	double := func(_ int, v int) int { return v * 2 }
+
+	for _, parallelism := range []int{1, 8} {
+		if n := m.TransformValues(double, parallelism); n != 1000 {
+			t.Errorf("Expected 1000 rewritten, got %d", n)
+		}
+	}
+	for i := range 1000 {
+		if v, _ := m.Get(i); v != i*4 {
+			t.Fatalf("Expected %d at %d, got %d", i*4, i, v)
+		}
+	}
+}
+
+func TestConcurrent_Janitor(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	expired := make(chan string, 10)
+	c := NewConcurrentWithConfig(ConcurrentConfig[string, int]{
+		Clock:           clk,
+		CleanupInterval: time.Second,
+		OnExpire: func(key string, _ int) {
+			expired <- key
+		},
+	})
+	defer c.Close()
+
+	c.SetTTL("a", 1, 500*time.Millisecond)
+	c.Set("b", 2)
+	clk.Advance(time.Second)
+
+	select {
+	case key := <-expired:
+		if key != "a" {
+			t.Errorf("Expected a expired, got %s", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Janitor did not remove expired entry")
+	}
+	if c.Len() != 1 {
+		t.Errorf("Expected 1 entry left, got %d", c.Len())
+	}
+
+	if err := c.Close(); err != nil {
+		t.Error("Close should be idempotent")
+	}
+}
+
+func TestConcurrent_OnExpireLazy(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	var got []string
+	c := NewConcurrentWithConfig(ConcurrentConfig[string, int]{
+		Clock:    clk,
+		OnExpire: func(key string, _ int) { got = append(got, key) },
+	})
+	c.SetTTL("a", 1, time.Second)
+	clk.Advance(2 * time.Second)
+	c.Get("a")
+	c.Get("a")
+	if len(got) != 1 || got[0] != "a" {
+		t.Errorf("Expected single OnExpire for a, got %v", got)
+	}
+}
+
+func TestConcurrent_Iterators(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig(ConcurrentConfig[string, int]{Clock: clk})
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.SetTTL("c", 3, time.Second)
+	clk.Advance(2 * time.Second)
+
+	sum := 0
+	for k, v := range c.All() {
+		if k == "c" {
+			t.Error("Expired entries should be skipped")
+		}
+		sum += v
+	}
+	if sum != 3 {
+		t.Errorf("Expected sum 3, got %d", sum)
+	}
+
+	keys := slices.Sorted(c.KeysSeq())
+	if !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+	values := slices.Sorted(c.ValuesSeq())
+	if !slices.Equal(values, []int{1, 2}) {
+		t.Errorf("Unexpected values %v", values)
+	}
+
+	for range c.All() {
+		break // early exit must not panic
+	}
+}
+
+func TestConcurrent_SlidingTTL(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig(ConcurrentConfig[string, int]{
+		Clock:             clk,
+		ExpireAfterAccess: 2 * time.Second,
+	})
+	c.SetTTL("a", 1, time.Second)
+	c.Set("b", 2)
+
+	if exp, ok := c.ExpireAt("a"); !ok || !exp.Equal(time.Unix(1, 0)) {
+		t.Errorf("Expected expiration at 1s, got %v %v", exp, ok)
+	}
+	if exp, ok := c.ExpireAt("b"); !ok || !exp.IsZero() {
+		t.Errorf("Expected zero expiration for b, got %v %v", exp, ok)
+	}
+
+	c.Get("a")
+	clk.Advance(1500 * time.Millisecond)
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Get should have extended the TTL")
+	}
+	if exp, _ := c.ExpireAt("b"); !exp.IsZero() {
+		t.Error("Entries without TTL should not gain one on access")
+	}
+
+	// Reads within an eighth of the TTL leave the entry alone
+	before, _ := c.ExpireAt("a")
+	clk.Advance(100 * time.Millisecond)
+	c.Get("a")
+	if after, _ := c.ExpireAt("a"); !after.Equal(before) {
+		t.Errorf("Expected expiration kept at %v, got %v", before, after)
+	}
+	clk.Advance(200 * time.Millisecond)
+	c.Get("a")
+	if after, _ := c.ExpireAt("a"); !after.Equal(clk.Now().Add(2 * time.Second)) {
+		t.Errorf("Expected expiration re-armed, got %v", after)
+	}
+
+	if !c.RefreshTTL("a", 0) {
+		t.Error("RefreshTTL should succeed on existing key")
+	}
+	clk.Advance(time.Hour)
+	if _, ok := c.Get("a"); !ok {
+		t.Error("RefreshTTL(0) should remove the expiration")
+	}
+	if c.RefreshTTL("missing", time.Second) {
+		t.Error("RefreshTTL should fail on missing key")
+	}
+	if _, ok := c.ExpireAt("missing"); ok {
+		t.Error("ExpireAt should fail on missing key")
+	}
+}
+
+func TestConcurrent_DefaultTTL(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig(ConcurrentConfig[string, int]{
+		Clock:      clk,
+		DefaultTTL: time.Minute,
+	})
+	c.Set("set", 1)
+	c.SetMany(map[string]int{"many": 2})
+	c.SetIfAbsent("absent", 3)
+	c.Swap("swap", 4)
+	c.Update("update", func(int, bool) int { return 5 })
+	c.SetTTL("forever", 6, 0)
+
+	for _, key := range []string{"set", "many", "absent", "swap", "update"} {
+		if exp, _ := c.ExpireAt(key); !exp.Equal(time.Unix(60, 0)) {
+			t.Errorf("Expected default TTL on %s, got %v", key, exp)
+		}
+	}
+	if exp, _ := c.ExpireAt("forever"); !exp.IsZero() {
+		t.Error("SetTTL with 0 should not apply the default TTL")
+	}
+
+	clk.Advance(2 * time.Minute)
+	if n := c.PurgeExpired(); n != 5 {
+		t.Errorf("Expected 5 expired, got %d", n)
+	}
+}
+
+func TestConcurrent_Batch(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig(ConcurrentConfig[string, int]{Clock: clk})
+	c.SetMany(map[string]int{"a": 1, "b": 2})
+	c.SetManyTTL(map[string]int{"c": 3, "d": 4}, time.Second)
+
+	got := c.GetMany([]string{"a", "c", "missing"})
+	if len(got) != 2 || got["a"] != 1 || got["c"] != 3 {
+		t.Errorf("Unexpected GetMany result %v", got)
+	}
+
+	clk.Advance(2 * time.Second)
+	if got := c.GetMany([]string{"c", "d"}); len(got) != 0 {
+		t.Errorf("Expired keys should be omitted, got %v", got)
+	}
+
+	if n := c.DeleteMany([]string{"a", "b", "missing"}); n != 2 {
+		t.Errorf("Expected 2 deleted, got %d", n)
+	}
+	if c.Has("a") || c.Has("b") {
+		t.Error("DeleteMany should remove keys")
+	}
+}
+
+func TestConcurrent_MaxSizeSampled(t *testing.T) {
+	c := NewConcurrentWithConfig(ConcurrentConfig[int, int]{MaxSize: 100})
+	for i := range 10_000 {
+		c.Set(i, i)
+		if i%3 == 1 {
+			c.Delete(i) // leaves a stale eviction candidate behind
+		}
+	}
+	if c.Len() != 100 {
+		t.Errorf("Expected 100 entries, got %d", c.Len())
+	}
+	pooled := 0
+	for i := range c.pool {
+		pooled += len(c.pool[i].keys)
+	}
+	if pooled > 4*100+minIndexCompact*len(c.pool) {
+		t.Errorf("Stale eviction candidates should be dropped, got %d", pooled)
+	}
+}
+
+func BenchmarkConcurrent_SetAtCapacity(b *testing.B) {
+	c := NewConcurrentWithConfig(ConcurrentConfig[int, int]{MaxSize: 100_000})
+	for i := range 100_000 {
+		c.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Set(100_000+i, i)
+	}
+}
+
+func TestConcurrent_MaxSize(t *testing.T) {
+	c := NewConcurrentWithConfig(ConcurrentConfig[int, int]{MaxSize: 10})
+	for i := range 100 {
+		c.Set(i, i)
+		if !c.Has(i) {
+			t.Fatalf("Just-written key %d should not be evicted", i)
+		}
+	}
+	if c.Len() != 10 {
+		t.Errorf("Expected 10 entries, got %d", c.Len())
+	}
+
+	clk := NewFakeClock(time.Unix(0, 0))
+	o := NewConcurrentWithConfig(ConcurrentConfig[string, int]{
+		Clock:    clk,
+		MaxSize:  2,
+		Eviction: EvictOldestExpiration,
+	})
+	o.Set("forever", 0)
+	o.SetTTL("soon", 1, time.Second)
+	o.SetTTL("later", 2, time.Hour)
+	if o.Has("soon") || !o.Has("forever") || !o.Has("later") {
+		t.Errorf("Expected soonest-expiring entry evicted, got keys %v", o.Keys())
+	}
+
+	o.SetTTL("short", 3, time.Second) // evicts "later"
+	clk.Advance(2 * time.Second)
+	o.Set("new", 4)
+	if !o.Has("forever") || !o.Has("new") {
+		t.Errorf("Expired entries should be dropped before evicting live ones, got keys %v", o.Keys())
+	}
+}
+
+func TestConcurrent_PurgeExpired(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	var expired []string
+	c := NewConcurrentWithConfig(ConcurrentConfig[string, int]{
+		Clock:    clk,
+		OnExpire: func(key string, _ int) { expired = append(expired, key) },
+	})
+	c.SetTTL("a", 1, time.Second)
+	c.SetTTL("b", 2, time.Second)
+	c.SetTTL("c", 3, time.Hour)
+	c.Set("d", 4)
+	c.SetTTL("b", 2, time.Hour) // overwrite leaves a stale index item
+	c.RefreshTTL("c", time.Second)
+
+	clk.Advance(2 * time.Second)
+	if n := c.PurgeExpired(); n != 2 {
+		t.Errorf("Expected 2 purged, got %d", n)
+	}
+	slices.Sort(expired)
+	if !slices.Equal(expired, []string{"a", "c"}) {
+		t.Errorf("Unexpected expired keys %v", expired)
+	}
+	if c.Len() != 2 || !c.Has("b") || !c.Has("d") {
+		t.Errorf("Expected b and d to remain, got %v", c.Keys())
+	}
+	if n := c.PurgeExpired(); n != 0 {
+		t.Errorf("Second purge should be a no-op, got %d", n)
+	}
+}
+
+func TestConcurrent_ExpiryIndexCompacts(t *testing.T) {
+	c := NewConcurrent[string, int]()
+	for i := range 1000 {
+		c.SetTTL("key", i, time.Hour)
+	}
+	for i := range c.expiry {
+		idx := &c.expiry[i]
+		idx.mu.Lock()
+		n := len(idx.h)
+		idx.mu.Unlock()
+		if n > minIndexCompact {
+			t.Errorf("Stale index items should be compacted, got %d in index %d", n, i)
+		}
+	}
+}
+
+func BenchmarkConcurrent_SetTTLParallel(b *testing.B) {
+	c := NewConcurrent[int, int]()
+	var seq atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(seq.Add(1)) << 10 // spread goroutines over the key space
+		for pb.Next() {
+			c.SetTTL(i&0xfffff, i, time.Hour)
+			i++
+		}
+	})
+}
+
+func TestConcurrent_JSON(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig(ConcurrentConfig[int, string]{Clock: clk})
+	c.Set(1, "one")
+	c.SetTTL(2, "two", 10*time.Second)
+	c.SetTTL(3, "three", time.Second)
+	clk.Advance(2 * time.Second)
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	restoredClk := NewFakeClock(time.Unix(100, 0))
+	restored := NewConcurrentWithConfig(ConcurrentConfig[int, string]{Clock: restoredClk})
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if restored.Len() != 2 {
+		t.Errorf("Expected 2 entries restored, got %d", restored.Len())
+	}
+	if v, _ := restored.Get(1); v != "one" {
+		t.Errorf("Expected one, got %q", v)
+	}
+	if exp, ok := restored.ExpireAt(2); !ok || !exp.Equal(time.Unix(108, 0)) {
+		t.Errorf("Expected remaining TTL of 8s, got %v %v", exp, ok)
+	}
+	if exp, _ := restored.ExpireAt(1); !exp.IsZero() {
+		t.Error("Entries without TTL should stay without TTL")
+	}
+
+	var zero Concurrent[int, string]
+	if err := json.Unmarshal(data, &zero); err != nil {
+		t.Fatalf("Unmarshal into zero value failed: %v", err)
+	}
+	if !zero.Has(1) || !zero.Has(2) {
+		t.Error("Zero Concurrent should be usable after Unmarshal")
+	}
+}
+
+func TestConcurrent_SaveLoad(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	c := NewConcurrentWithConfig(ConcurrentConfig[string, int]{Clock: clk})
+	c.Set("a", 1)
+	c.SetTTL("b", 2, 10*time.Second)
+	c.SetTTL("c", 3, 5*time.Second)
+	c.SetTTL("gone", 4, time.Second)
+	clk.Advance(2 * time.Second)
+
+	var buf bytes.Buffer
+	if err := c.SaveTo(&buf); err != nil {
+		t.Fatalf("SaveTo failed: %v", err)
+	}
+
+	succClk := NewFakeClock(time.Unix(6, 0))
+	succ := NewConcurrentWithConfig(ConcurrentConfig[string, int]{Clock: succClk})
+	if err := succ.LoadFrom(&buf); err != nil {
+		t.Fatalf("LoadFrom failed: %v", err)
+	}
+	keys := succ.Keys()
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("Expected a and b restored, got %v", keys)
+	}
+	if exp, _ := succ.ExpireAt("b"); !exp.Equal(time.Unix(10, 0)) {
+		t.Errorf("Expected absolute expiration kept, got %v", exp)
+	}
+
+	if err := succ.LoadFrom(bytes.NewReader([]byte("junk"))); err == nil {
+		t.Error("LoadFrom should fail on invalid input")
+	}
+}
+
+func TestConcurrent_GetOrCompute(t *testing.T) {
+	c := NewConcurrent[string, int]()
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]int, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := c.GetOrCompute("key", func() (int, time.Duration, error) {
+				calls.Add(1)
+				<-release
+				return 42, time.Minute, nil
+			})
+			if err != nil {
+				t.Errorf("Unexpected error %v", err)
+			}
+			results[i] = v
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected loader to run once, ran %d times", n)
+	}
+	for _, v := range results {
+		if v != 42 {
+			t.Errorf("Expected 42, got %d", v)
+		}
+	}
+	if exp, ok := c.ExpireAt("key"); !ok || exp.IsZero() {
+		t.Error("Loaded value should be stored with its TTL")
+	}
+
+	errBoom := errors.New("boom")
+	if _, err := c.GetOrCompute("fail", func() (int, time.Duration, error) {
+		return 0, 0, errBoom
+	}); !errors.Is(err, errBoom) {
+		t.Errorf("Expected loader error, got %v", err)
+	}
+	if c.Has("fail") {
+		t.Error("Failed loads should not be stored")
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		c.GetOrCompute("panic", func() (int, time.Duration, error) { panic("boom") })
+	}()
+	if v, err := c.GetOrCompute("panic", func() (int, time.Duration, error) {
+		return 7, 0, nil
+	}); err != nil || v != 7 {
+		t.Errorf("Key should be loadable after a panic, got %d %v", v, err)
+	}
+}
+
 // ==================== BENCHMARKS ====================
 
 func BenchmarkConcurrent_Set(b *testing.B) {
@@ -0,0 +1,14 @@
+package mappo
+
+import "reflect"
+
+// defaultEqual returns the equality used by CompareAndSwap/CompareAndDelete when
+// the caller doesn't supply one: == for comparable types, reflect.DeepEqual otherwise.
+// Interface types use DeepEqual since == panics on non-comparable dynamic values.
+func defaultEqual[V any]() func(a, b V) bool {
+	t := reflect.TypeFor[V]()
+	if t.Kind() != reflect.Interface && t.Comparable() {
+		return func(a, b V) bool { return any(a) == any(b) }
+	}
+	return func(a, b V) bool { return reflect.DeepEqual(a, b) }
+}
@@ -0,0 +1,73 @@
+package mappo
+
+import "sync"
+
+// flightGroup deduplicates concurrent loads of the same key, for
+// Concurrent.GetOrCompute and ShardedLoader.Get. The zero value is ready to use.
+type flightGroup[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*flightCall[V] // created lazily
+}
+
+// flightCall is an in-flight load shared by all callers of a key.
+type flightCall[V any] struct {
+	done     chan struct{}
+	value    V
+	err      error
+	panicked bool
+}
+
+// do returns the value get finds for key, or runs load to produce it.
+// Only one load runs per key at a time; concurrent callers wait for its
+// result, error included. load is expected to store what it returns.
+// If load panics, the panic propagates to its caller and waiters retry.
+func (g *flightGroup[K, V]) do(key K, get func(K) (V, bool), load func() (V, error)) (V, error) {
+	for {
+		if v, ok := get(key); ok {
+			return v, nil
+		}
+
+		g.mu.Lock()
+		if call, ok := g.calls[key]; ok {
+			g.mu.Unlock()
+			<-call.done
+			if call.panicked {
+				continue
+			}
+			return call.value, call.err
+		}
+		call := &flightCall[V]{done: make(chan struct{})}
+		if g.calls == nil {
+			g.calls = make(map[K]*flightCall[V])
+		}
+		g.calls[key] = call
+		g.mu.Unlock()
+
+		g.run(key, call, get, load)
+		return call.value, call.err
+	}
+}
+
+// run loads key for call and releases its waiters.
+func (g *flightGroup[K, V]) run(key K, call *flightCall[V], get func(K) (V, bool), load func() (V, error)) {
+	call.panicked = true
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	// A previous flight may have stored the value after our get
+	if v, ok := get(key); ok {
+		call.value, call.panicked = v, false
+		return
+	}
+	v, err := load()
+	call.panicked = false
+	if err != nil {
+		call.err = err
+		return
+	}
+	call.value = v
+}
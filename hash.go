@@ -1,12 +1,26 @@
 package mappo
 
 import (
+	"fmt"
 	"hash/maphash"
+	"reflect"
 	"unsafe"
 )
 
+// Hasher hashes a key for shard selection. Equal keys must hash equally for
+// the same seed; a hasher may ignore the seed (e.g. xxhash).
+type Hasher[K comparable] func(key K, seed maphash.Seed) uint64
+
+// DefaultHasher returns the maphash-based hasher Sharded uses when none is
+// configured. For key types other than string and 64-bit integers it hashes
+// the key's raw memory, so keys holding pointers, strings or interfaces
+// need a custom Hasher to shard by value.
+func DefaultHasher[K comparable]() Hasher[K] {
+	return makeHasher[K]()
+}
+
 // makeHasher creates a type-specific hash function.
-func makeHasher[K comparable]() func(K, maphash.Seed) uint64 {
+func makeHasher[K comparable]() Hasher[K] {
 	var zero K
 	switch any(zero).(type) {
 	case string:
@@ -38,3 +52,102 @@ func makeHasher[K comparable]() func(K, maphash.Seed) uint64 {
 		}
 	}
 }
+
+// SeededHasher returns a hasher that ignores the maphash seed and derives
+// every hash from seed alone, so the key to shard mapping is the same in every
+// process. It hashes keys by value: string kinds use FNV-1a, and integer and
+// bool kinds a splitmix64 mix, both fixed algorithms. Any other key kind
+// panics, since its memory may hold pointers or padding that differ between
+// equal keys; use SeededHasherFunc or a custom Hasher for those.
+func SeededHasher[K comparable](seed uint64) Hasher[K] {
+	switch reflect.TypeFor[K]().Kind() {
+	case reflect.String:
+		return func(k K, _ maphash.Seed) uint64 {
+			return fnvString(seed, *(*string)(unsafe.Pointer(&k)))
+		}
+	case reflect.Bool:
+		return func(k K, _ maphash.Seed) uint64 {
+			var b uint64
+			if *(*bool)(unsafe.Pointer(&k)) {
+				b = 1
+			}
+			return mix64(b ^ seed)
+		}
+	case reflect.Int:
+		return func(k K, _ maphash.Seed) uint64 {
+			return mix64(uint64(*(*int)(unsafe.Pointer(&k))) ^ seed)
+		}
+	case reflect.Int8:
+		return func(k K, _ maphash.Seed) uint64 {
+			return mix64(uint64(*(*int8)(unsafe.Pointer(&k))) ^ seed)
+		}
+	case reflect.Int16:
+		return func(k K, _ maphash.Seed) uint64 {
+			return mix64(uint64(*(*int16)(unsafe.Pointer(&k))) ^ seed)
+		}
+	case reflect.Int32:
+		return func(k K, _ maphash.Seed) uint64 {
+			return mix64(uint64(*(*int32)(unsafe.Pointer(&k))) ^ seed)
+		}
+	case reflect.Int64:
+		return func(k K, _ maphash.Seed) uint64 {
+			return mix64(uint64(*(*int64)(unsafe.Pointer(&k))) ^ seed)
+		}
+	case reflect.Uint:
+		return func(k K, _ maphash.Seed) uint64 {
+			return mix64(uint64(*(*uint)(unsafe.Pointer(&k))) ^ seed)
+		}
+	case reflect.Uint8:
+		return func(k K, _ maphash.Seed) uint64 {
+			return mix64(uint64(*(*uint8)(unsafe.Pointer(&k))) ^ seed)
+		}
+	case reflect.Uint16:
+		return func(k K, _ maphash.Seed) uint64 {
+			return mix64(uint64(*(*uint16)(unsafe.Pointer(&k))) ^ seed)
+		}
+	case reflect.Uint32:
+		return func(k K, _ maphash.Seed) uint64 {
+			return mix64(uint64(*(*uint32)(unsafe.Pointer(&k))) ^ seed)
+		}
+	case reflect.Uint64:
+		return func(k K, _ maphash.Seed) uint64 {
+			return mix64(*(*uint64)(unsafe.Pointer(&k)) ^ seed)
+		}
+	case reflect.Uintptr:
+		return func(k K, _ maphash.Seed) uint64 {
+			return mix64(uint64(*(*uintptr)(unsafe.Pointer(&k))) ^ seed)
+		}
+	}
+	panic(fmt.Sprintf("mappo: SeededHasher cannot hash %v by value; use SeededHasherFunc", reflect.TypeFor[K]()))
+}
+
+// SeededHasherFunc is SeededHasher for any key type: encode appends a
+// canonical byte form of the key to dst, and the hash is FNV-1a over those
+// bytes. Equal keys must encode identically, e.g. by writing struct fields
+// one by one and normalizing -0 to 0 for floats.
+func SeededHasherFunc[K comparable](seed uint64, encode func(dst []byte, key K) []byte) Hasher[K] {
+	return func(k K, _ maphash.Seed) uint64 {
+		var buf [64]byte
+		b := encode(buf[:0], k)
+		return fnvString(seed, unsafe.String(unsafe.SliceData(b), len(b)))
+	}
+}
+
+// fnvString is FNV-1a over s with seed folded into the offset basis,
+// finished with mix64 so the low bits used for shard selection are well spread.
+func fnvString(seed uint64, s string) uint64 {
+	h := uint64(14695981039346656037) ^ seed
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+	return mix64(h)
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
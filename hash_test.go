@@ -1,7 +1,9 @@
 package mappo
 
 import (
+	"encoding/binary"
 	"hash/maphash"
+	"math"
 	"testing"
 	"unsafe"
 )
@@ -88,6 +90,64 @@ func TestMakeHasher(t *testing.T) {
 	})
 }
 
+func TestSeededHasher(t *testing.T) {
+	// Golden values: changing them breaks shard files persisted by users
+	var seed maphash.Seed
+	if h := SeededHasher[string](42)("tenant:42", seed); h != 0xb0f0b8a45464f6d9 {
+		t.Errorf("string hash changed: %#x", h)
+	}
+	if h := SeededHasher[int](42)(7, seed); h != 0xf7e9f3f88cc04ad6 {
+		t.Errorf("int hash changed: %#x", h)
+	}
+
+	// The maphash seed is ignored
+	hasher := SeededHasher[string](42)
+	if hasher("a", maphash.MakeSeed()) != hasher("a", maphash.MakeSeed()) {
+		t.Error("hash should not depend on the maphash seed")
+	}
+	if hasher("a", seed) == SeededHasher[string](43)("a", seed) {
+		t.Error("hash should depend on the seed")
+	}
+
+	// Named string types hash their contents, not their header
+	type tenant string
+	named := SeededHasher[tenant](42)
+	if named(tenant("tenant:42"), seed) != hasher("tenant:42", seed) {
+		t.Error("named string should hash like string")
+	}
+
+	type shardID uint16
+	if SeededHasher[shardID](42)(7, seed) != SeededHasher[uint16](42)(7, seed) {
+		t.Error("named integer should hash like its underlying type")
+	}
+	if SeededHasher[bool](42)(true, seed) == SeededHasher[bool](42)(false, seed) {
+		t.Error("bools should hash differently")
+	}
+
+	// Keys that can't be hashed by value are rejected up front
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for struct keys")
+		}
+	}()
+	SeededHasher[struct{ a, b string }](42)
+}
+
+func TestSeededHasherFunc(t *testing.T) {
+	type point struct{ x, y float64 }
+	hasher := SeededHasherFunc(42, func(dst []byte, p point) []byte {
+		dst = binary.LittleEndian.AppendUint64(dst, math.Float64bits(p.x+0)) // +0 folds -0 into 0
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(p.y+0))
+	})
+	var seed maphash.Seed
+	if hasher(point{0, 1}, seed) != hasher(point{math.Copysign(0, -1), 1}, seed) {
+		t.Error("equal keys should hash equally")
+	}
+	if hasher(point{0, 1}, seed) == hasher(point{1, 0}, seed) {
+		t.Error("same hash for different keys")
+	}
+}
+
 func BenchmarkMakeHasherString(b *testing.B) {
 	hasher := makeHasher[string]()
 	seed := maphash.MakeSeed()
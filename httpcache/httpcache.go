@@ -0,0 +1,266 @@
+// Package httpcache provides an http.Handler middleware that caches GET
+// responses in a mappo.Cache.
+//
+// Responses are keyed by method, host, URL and the request headers named in
+// the response's Vary header. The TTL comes from the response's Cache-Control
+// (s-maxage, then max-age), falling back to Options.DefaultTTL.
+//
+// As a shared cache it never stores responses that set cookies, nor responses
+// to requests carrying Authorization unless the response explicitly allows it
+// with public, s-maxage or must-revalidate (RFC 9111 §3.5).
+package httpcache
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/mappo"
+)
+
+// HeaderCache is set on every cached-path response to "HIT" or "MISS".
+const HeaderCache = "X-Cache"
+
+// Options configures the middleware.
+type Options struct {
+	// DefaultTTL applies when the response has no max-age directive.
+	// Zero means such responses are not cached.
+	DefaultTTL time.Duration
+	// MaxBodySize skips caching responses with larger bodies (0 = unlimited).
+	MaxBodySize int
+}
+
+// response is the cached form of an HTTP response.
+type response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// cacheableStatus lists status codes cacheable by default (RFC 9110 §15.1).
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// Middleware returns a function wrapping handlers with New.
+func Middleware(c *mappo.Cache, opt Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return New(next, c, opt)
+	}
+}
+
+// New wraps next so that cacheable GET responses are served from c.
+func New(next http.Handler, c *mappo.Cache, opt Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || bypass(r.Header) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		base := r.Method + " " + r.Host + " " + r.URL.String()
+		if vary, ok := mappo.LoadTyped[[]string](c, varyKey(base)); ok {
+			if resp, ok := mappo.LoadTyped[*response](c, variantKey(base, vary, r.Header)); ok {
+				writeResponse(w, resp, "HIT")
+				return
+			}
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK, limit: opt.MaxBodySize}
+		rec.Header().Set(HeaderCache, "MISS")
+		next.ServeHTTP(rec, r)
+
+		ttl, ok := responseTTL(rec.Header(), opt.DefaultTTL)
+		if !ok || rec.overflow || !cacheableStatus[rec.status] || !shareable(r.Header, rec.Header()) {
+			return
+		}
+		vary := parseVary(rec.Header())
+		if vary == nil {
+			return
+		}
+		header := rec.Header().Clone()
+		header.Del(HeaderCache)
+		resp := &response{Status: rec.status, Header: header, Body: rec.body.Bytes()}
+		mappo.StoreTyped(c, varyKey(base), vary, ttl)
+		mappo.StoreTyped(c, variantKey(base, vary, r.Header), resp, ttl)
+	})
+}
+
+// bypass reports whether the request asks not to be served from cache.
+func bypass(h http.Header) bool {
+	for _, d := range directives(h) {
+		if d == "no-cache" || d == "no-store" {
+			return true
+		}
+	}
+	return false
+}
+
+// shareable reports whether a response may be stored in a cache shared
+// between clients. Set-Cookie would hand one client's session to the next,
+// and authorized responses are private unless the origin says otherwise.
+func shareable(req, resp http.Header) bool {
+	if len(resp.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	if req.Get("Authorization") == "" {
+		return true
+	}
+	for _, d := range directives(resp) {
+		name, _, _ := strings.Cut(d, "=")
+		if name == "public" || name == "s-maxage" || name == "must-revalidate" {
+			return true
+		}
+	}
+	return false
+}
+
+// responseTTL derives the TTL from Cache-Control. Returns false if the
+// response must not be cached.
+func responseTTL(h http.Header, fallback time.Duration) (time.Duration, bool) {
+	maxAge, sMaxAge := -1, -1
+	for _, d := range directives(h) {
+		name, value, _ := strings.Cut(d, "=")
+		switch name {
+		case "no-store", "no-cache", "private":
+			return 0, false
+		case "max-age":
+			if n, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				maxAge = n
+			}
+		case "s-maxage":
+			if n, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				sMaxAge = n
+			}
+		}
+	}
+	switch {
+	case sMaxAge >= 0:
+		maxAge = sMaxAge
+	case maxAge < 0:
+		return fallback, fallback > 0
+	}
+	if maxAge == 0 {
+		return 0, false
+	}
+	return time.Duration(maxAge) * time.Second, true
+}
+
+// directives returns the lower-cased Cache-Control directives.
+func directives(h http.Header) []string {
+	var out []string
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+				out = append(out, d)
+			}
+		}
+	}
+	return out
+}
+
+// parseVary returns the canonical, sorted Vary header names.
+// Returns nil for "Vary: *", which is never cacheable.
+func parseVary(h http.Header) []string {
+	vary := []string{}
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil
+			}
+			if name != "" {
+				vary = append(vary, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(vary)
+	return vary
+}
+
+func varyKey(base string) string {
+	return "httpcache:vary:" + base
+}
+
+func variantKey(base string, vary []string, h http.Header) string {
+	var b strings.Builder
+	b.WriteString("httpcache:resp:")
+	b.WriteString(base)
+	for _, name := range vary {
+		b.WriteByte('\n')
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(h.Values(name), ","))
+	}
+	return b.String()
+}
+
+func writeResponse(w http.ResponseWriter, resp *response, status string) {
+	dst := w.Header()
+	for k, v := range resp.Header {
+		dst[k] = append([]string(nil), v...)
+	}
+	dst.Set(HeaderCache, status)
+	w.WriteHeader(resp.Status)
+	_, _ = w.Write(resp.Body)
+}
+
+// recorder tees the response to the client while capturing it.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	limit       int
+	overflow    bool
+	wroteHeader bool
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if !r.overflow {
+		if r.limit > 0 && r.body.Len()+len(p) > r.limit {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(p)
+		}
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher for handlers that assert it directly, sending
+// buffered data to the client. The recorder keeps its copy of everything
+// written, so a flushed response is still cached whole.
+func (r *recorder) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
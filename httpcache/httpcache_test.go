@@ -0,0 +1,220 @@
+package httpcache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/olekukonko/mappo"
+)
+
+func newCache() *mappo.Cache {
+	return mappo.NewCache(mappo.CacheOptions{MaximumSize: 100})
+}
+
+func do(h http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware_MaxAge(t *testing.T) {
+	calls := 0
+	h := Middleware(newCache(), Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprintf(w, "body %d", calls)
+	}))
+
+	first := do(h, "/a", nil)
+	second := do(h, "/a", nil)
+	if calls != 1 {
+		t.Errorf("expected handler called once, got %d", calls)
+	}
+	if first.Header().Get(HeaderCache) != "MISS" || second.Header().Get(HeaderCache) != "HIT" {
+		t.Error("expected MISS then HIT")
+	}
+	if second.Body.String() != "body 1" {
+		t.Errorf("expected cached body, got %q", second.Body.String())
+	}
+}
+
+func TestMiddleware_NoStore(t *testing.T) {
+	calls := 0
+	h := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "no-store")
+	}), newCache(), Options{DefaultTTL: time.Minute})
+
+	do(h, "/a", nil)
+	do(h, "/a", nil)
+	if calls != 2 {
+		t.Errorf("expected no-store responses not cached, got %d calls", calls)
+	}
+}
+
+func TestMiddleware_DefaultTTL(t *testing.T) {
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+
+	h := New(handler, newCache(), Options{})
+	do(h, "/a", nil)
+	do(h, "/a", nil)
+	if calls != 2 {
+		t.Errorf("expected no caching without max-age or DefaultTTL, got %d calls", calls)
+	}
+
+	calls = 0
+	h = New(handler, newCache(), Options{DefaultTTL: time.Minute})
+	do(h, "/a", nil)
+	do(h, "/a", nil)
+	if calls != 1 {
+		t.Errorf("expected DefaultTTL to enable caching, got %d calls", calls)
+	}
+}
+
+func TestMiddleware_Vary(t *testing.T) {
+	calls := 0
+	h := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		fmt.Fprint(w, r.Header.Get("Accept-Language"))
+	}), newCache(), Options{})
+
+	en := http.Header{"Accept-Language": {"en"}}
+	fr := http.Header{"Accept-Language": {"fr"}}
+	do(h, "/a", en)
+	if got := do(h, "/a", fr).Body.String(); got != "fr" {
+		t.Errorf("expected fr variant, got %q", got)
+	}
+	if got := do(h, "/a", en); got.Body.String() != "en" || got.Header().Get(HeaderCache) != "HIT" {
+		t.Error("expected cached en variant")
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 handler calls, got %d", calls)
+	}
+}
+
+func TestMiddleware_NonGetAndErrors(t *testing.T) {
+	calls := 0
+	h := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusInternalServerError)
+	}), newCache(), Options{})
+
+	do(h, "/a", nil)
+	do(h, "/a", nil)
+	req := httptest.NewRequest(http.MethodPost, "/a", nil)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if calls != 3 {
+		t.Errorf("expected errors and POST uncached, got %d calls", calls)
+	}
+}
+
+func TestResponseTTL(t *testing.T) {
+	h := http.Header{"Cache-Control": {"public, max-age=10, s-maxage=30"}}
+	if ttl, ok := responseTTL(h, 0); !ok || ttl != 30*time.Second {
+		t.Errorf("expected s-maxage to win, got %v %v", ttl, ok)
+	}
+	h = http.Header{"Cache-Control": {"private, max-age=10"}}
+	if _, ok := responseTTL(h, time.Minute); ok {
+		t.Error("expected private responses uncached")
+	}
+}
+
+func TestMiddleware_Authorization(t *testing.T) {
+	calls := 0
+	cacheControl := "max-age=60"
+	h := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", cacheControl)
+		fmt.Fprint(w, r.Header.Get("Authorization"))
+	}), newCache(), Options{})
+
+	alice := http.Header{"Authorization": {"Bearer alice"}}
+	bob := http.Header{"Authorization": {"Bearer bob"}}
+	do(h, "/me", alice)
+	if got := do(h, "/me", bob).Body.String(); got != "Bearer bob" {
+		t.Errorf("expected authorized response not shared, got %q", got)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 handler calls, got %d", calls)
+	}
+
+	cacheControl = "public, max-age=60"
+	do(h, "/public", alice)
+	if got := do(h, "/public", bob); got.Header().Get(HeaderCache) != "HIT" {
+		t.Error("expected public authorized response cached")
+	}
+}
+
+func TestMiddleware_SetCookie(t *testing.T) {
+	calls := 0
+	h := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=60")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: fmt.Sprint(calls)})
+	}), newCache(), Options{})
+
+	do(h, "/a", nil)
+	second := do(h, "/a", nil)
+	if calls != 2 || second.Header().Get(HeaderCache) == "HIT" {
+		t.Errorf("expected Set-Cookie responses uncached, got %d calls", calls)
+	}
+}
+
+func TestMiddleware_Host(t *testing.T) {
+	h := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, r.Host)
+	}), newCache(), Options{})
+
+	get := func(host string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/a", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	get("a.example")
+	if got := get("b.example").Body.String(); got != "b.example" {
+		t.Errorf("expected hosts cached separately, got %q", got)
+	}
+	if got := get("a.example"); got.Body.String() != "a.example" || got.Header().Get(HeaderCache) != "HIT" {
+		t.Error("expected cached a.example response")
+	}
+}
+
+func TestMiddleware_Flush(t *testing.T) {
+	calls := 0
+	h := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, "head ")
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("expected the middleware's writer to implement http.Flusher")
+		}
+		f.Flush()
+		fmt.Fprint(w, "tail")
+	}), newCache(), Options{})
+
+	first := do(h, "/a", nil)
+	if !first.Flushed {
+		t.Error("expected Flush to reach the underlying writer")
+	}
+	second := do(h, "/a", nil)
+	if calls != 1 || second.Header().Get(HeaderCache) != "HIT" || second.Body.String() != "head tail" {
+		t.Errorf("expected the whole flushed response cached, got %d calls and %q", calls, second.Body.String())
+	}
+}
@@ -13,6 +13,8 @@ type LRUConfig[K comparable, V any] struct {
 	MaxSize    int
 	TTL        time.Duration
 	OnEviction func(key K, value V)
+	// Clock is the time source for TTLs. Optional, nil uses SystemClock.
+	Clock Clock
 }
 
 // lruNode is an intrusive list node stored in the node pool.
@@ -29,6 +31,7 @@ type LRU[K comparable, V any] struct {
 	maxSize    int
 	defaultTTL time.Duration
 	onEviction func(K, V)
+	clock      Clock
 	m          *xsync.MapOf[K, int64]
 	listMu     sync.Mutex
 	head       int64
@@ -52,6 +55,7 @@ func NewLRUWithConfig[K comparable, V any](cfg LRUConfig[K, V]) *LRU[K, V] {
 		maxSize:    cfg.MaxSize,
 		defaultTTL: cfg.TTL,
 		onEviction: cfg.OnEviction,
+		clock:      clockOrSystem(cfg.Clock),
 		m:          xsync.NewMapOf[K, int64](),
 		nodePool:   make([]lruNode[K, V], 0, cfg.MaxSize),
 		head:       -1,
@@ -147,7 +151,7 @@ func (l *LRU[K, V]) Set(key K, value V) {
 func (l *LRU[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
 	var exp int64
 	if ttl > 0 {
-		exp = time.Now().Add(ttl).UnixNano()
+		exp = l.clock.Now().Add(ttl).UnixNano()
 	}
 
 	l.listMu.Lock()
@@ -212,7 +216,7 @@ func (l *LRU[K, V]) Get(key K) (V, bool) {
 		return zero, false
 	}
 
-	if node.expiration > 0 && time.Now().UnixNano() > node.expiration {
+	if node.expiration > 0 && l.clock.Now().UnixNano() > node.expiration {
 		l.removeFromList(idx)
 		l.m.Delete(key)
 		l.releaseNode(idx)
@@ -247,7 +251,7 @@ func (l *LRU[K, V]) Peek(key K) (V, bool) {
 		return zero, false
 	}
 
-	if node.expiration > 0 && time.Now().UnixNano() > node.expiration {
+	if node.expiration > 0 && l.clock.Now().UnixNano() > node.expiration {
 		var zero V
 		return zero, false
 	}
@@ -311,7 +315,7 @@ func (l *LRU[K, V]) Keys() []K {
 	defer l.listMu.Unlock()
 
 	keys := make([]K, 0, l.Len())
-	now := time.Now().UnixNano()
+	now := l.clock.Now().UnixNano()
 	for idx := l.head; idx >= 0; {
 		if idx >= int64(len(l.nodePool)) {
 			break
@@ -331,7 +335,7 @@ func (l *LRU[K, V]) Values() []V {
 	defer l.listMu.Unlock()
 
 	values := make([]V, 0, l.Len())
-	now := time.Now().UnixNano()
+	now := l.clock.Now().UnixNano()
 	for idx := l.head; idx >= 0; {
 		if idx >= int64(len(l.nodePool)) {
 			break
@@ -350,7 +354,7 @@ func (l *LRU[K, V]) Range(fn func(K, V) bool) {
 	l.listMu.Lock()
 	defer l.listMu.Unlock()
 
-	now := time.Now().UnixNano()
+	now := l.clock.Now().UnixNano()
 	for idx := l.head; idx >= 0; {
 		if idx >= int64(len(l.nodePool)) {
 			break
@@ -374,7 +378,7 @@ func (l *LRU[K, V]) GetOrSet(key K, value V, ttl time.Duration) (V, bool) {
 
 	var exp int64
 	if ttl > 0 {
-		exp = time.Now().Add(ttl).UnixNano()
+		exp = l.clock.Now().Add(ttl).UnixNano()
 	}
 
 	l.listMu.Lock()
@@ -383,7 +387,7 @@ func (l *LRU[K, V]) GetOrSet(key K, value V, ttl time.Duration) (V, bool) {
 	if idx, ok := l.m.Load(key); ok {
 		if idx >= 0 && idx < int64(len(l.nodePool)) {
 			node := &l.nodePool[idx]
-			if node.key == key && (node.expiration == 0 || time.Now().UnixNano() <= node.expiration) {
+			if node.key == key && (node.expiration == 0 || l.clock.Now().UnixNano() <= node.expiration) {
 				l.moveToFront(idx)
 				return node.value, true
 			}
@@ -453,7 +457,7 @@ func (l *LRU[K, V]) PurgeExpired() int {
 	l.listMu.Lock()
 	defer l.listMu.Unlock()
 
-	now := time.Now().UnixNano()
+	now := l.clock.Now().UnixNano()
 	removed := 0
 	for idx := l.head; idx >= 0; {
 		if idx >= int64(len(l.nodePool)) {
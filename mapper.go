@@ -1,10 +1,22 @@
 package mappo
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"iter"
+	"maps"
+	"math/rand/v2"
+	"slices"
 	"sort"
 )
 
+// ErrDuplicateValue is returned by TryInvert when two keys share a value.
+var ErrDuplicateValue = errors.New("mappo: duplicate value")
+
+// ErrLengthMismatch is returned by Zip when keys and values differ in length.
+var ErrLengthMismatch = errors.New("mappo: length mismatch")
+
 // KeyValuePair represents a single key-value pair.
 type KeyValuePair[K comparable, V any] struct {
 	Key   K
@@ -33,6 +45,25 @@ func NewMapperWithCapacity[K comparable, V any](capacity int) Mapper[K, V] {
 	return make(Mapper[K, V], capacity)
 }
 
+// NewMapperFromPairs creates a Mapper from key-value pairs, the inverse of
+// ToSlice. When keys repeat the last pair wins.
+func NewMapperFromPairs[K comparable, V any](pairs []KeyValuePair[K, V]) Mapper[K, V] {
+	m := make(Mapper[K, V], len(pairs))
+	for _, p := range pairs {
+		m[p.Key] = p.Value
+	}
+	return m
+}
+
+// NewMapperFromKeys creates a Mapper with a value computed by valueFn for each key.
+func NewMapperFromKeys[K comparable, V any](keys []K, valueFn func(K) V) Mapper[K, V] {
+	m := make(Mapper[K, V], len(keys))
+	for _, k := range keys {
+		m[k] = valueFn(k)
+	}
+	return m
+}
+
 // Get returns the value associated with the key.
 // If the key doesn't exist, returns the zero value.
 func (m Mapper[K, V]) Get(key K) V {
@@ -106,6 +137,31 @@ func (m Mapper[K, V]) Delete(key K) Mapper[K, V] {
 	return m
 }
 
+// DeleteMany removes the given keys and returns how many existed.
+func (m Mapper[K, V]) DeleteMany(keys ...K) int {
+	removed := 0
+	for _, k := range keys {
+		if _, ok := m[k]; ok {
+			delete(m, k)
+			removed++
+		}
+	}
+	return removed
+}
+
+// DeleteIf removes, in place, every pair for which fn returns true and
+// returns how many were removed. Unlike Filter it allocates nothing.
+func (m Mapper[K, V]) DeleteIf(fn func(K, V) bool) int {
+	removed := 0
+	for k, v := range m {
+		if fn(k, v) {
+			delete(m, k)
+			removed++
+		}
+	}
+	return removed
+}
+
 // Has returns true if the key exists.
 func (m Mapper[K, V]) Has(key K) bool {
 	if m == nil {
@@ -169,6 +225,24 @@ func (m Mapper[K, V]) Range(fn func(K, V)) {
 	}
 }
 
+// All returns an iterator over all key-value pairs.
+// API matches Concurrent.All
+func (m Mapper[K, V]) All() iter.Seq2[K, V] {
+	return maps.All(m)
+}
+
+// KeysSeq returns an iterator over all keys.
+// API matches Concurrent.KeysSeq
+func (m Mapper[K, V]) KeysSeq() iter.Seq[K] {
+	return maps.Keys(m)
+}
+
+// ValuesSeq returns an iterator over all values.
+// API matches Concurrent.ValuesSeq
+func (m Mapper[K, V]) ValuesSeq() iter.Seq[V] {
+	return maps.Values(m)
+}
+
 // Filter returns a new Mapper containing only pairs that satisfy the predicate.
 func (m Mapper[K, V]) Filter(fn func(K, V) bool) Mapper[K, V] {
 	if m == nil || len(m) == 0 {
@@ -183,6 +257,32 @@ func (m Mapper[K, V]) Filter(fn func(K, V) bool) Mapper[K, V] {
 	return result
 }
 
+// Every reports whether fn holds for all pairs, stopping at the first failure.
+// It returns true for an empty map.
+func (m Mapper[K, V]) Every(fn func(K, V) bool) bool {
+	for k, v := range m {
+		if !fn(k, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Some reports whether fn holds for at least one pair, stopping at the first match.
+func (m Mapper[K, V]) Some(fn func(K, V) bool) bool {
+	for k, v := range m {
+		if fn(k, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// None reports whether fn holds for no pair. It returns true for an empty map.
+func (m Mapper[K, V]) None(fn func(K, V) bool) bool {
+	return !m.Some(fn)
+}
+
 // MapValues returns a new Mapper with transformed values.
 func (m Mapper[K, V]) MapValues(fn func(V) V) Mapper[K, V] {
 	if m == nil || len(m) == 0 {
@@ -195,6 +295,15 @@ func (m Mapper[K, V]) MapValues(fn func(V) V) Mapper[K, V] {
 	return result
 }
 
+// Apply rewrites every value in place with fn, which also receives the key,
+// and returns m. Unlike MapValues it allocates no new map.
+func (m Mapper[K, V]) Apply(fn func(K, V) V) Mapper[K, V] {
+	for k, v := range m {
+		m[k] = fn(k, v)
+	}
+	return m
+}
+
 // MapKeys returns a new Mapper with keys transformed by fn.
 // Panics if two keys map to the same value.
 func (m Mapper[K, V]) MapKeys(fn func(K) K) Mapper[K, V] {
@@ -250,6 +359,8 @@ func (m Mapper[K, V]) ToSlice() []KeyValuePair[K, V] {
 }
 
 // SortedKeys returns keys sorted by natural order (if possible).
+// For ordered key types SortedKeysOrdered is much faster; for other types
+// SortedKeysFunc avoids the per-comparison type switch.
 func (m Mapper[K, V]) SortedKeys() []K {
 	keys := m.Keys()
 	if len(keys) == 0 {
@@ -326,6 +437,140 @@ func (m Mapper[K, V]) SortedKeys() []K {
 	return keys
 }
 
+// SortedByValue returns all entries ordered by value, with less reporting
+// whether a sorts before b. Entries with equal values are in random order.
+func (m Mapper[K, V]) SortedByValue(less func(a, b V) bool) []KeyValuePair[K, V] {
+	pairs := m.ToSlice()
+	sort.Slice(pairs, func(i, j int) bool { return less(pairs[i].Value, pairs[j].Value) })
+	return pairs
+}
+
+// TopN returns the first n entries of SortedByValue without sorting the whole
+// map: it keeps a bounded heap, so the cost is O(len * log n).
+// Pass a greater-than comparison for leaderboard style "highest n" queries.
+func (m Mapper[K, V]) TopN(n int, less func(a, b V) bool) []KeyValuePair[K, V] {
+	if n <= 0 || len(m) == 0 {
+		return nil
+	}
+	if n >= len(m) {
+		return m.SortedByValue(less)
+	}
+	// top is a heap whose root is the entry that sorts last, i.e. the next to go
+	top := make([]KeyValuePair[K, V], 0, n)
+	after := func(i, j int) bool { return less(top[j].Value, top[i].Value) }
+	for k, v := range m {
+		if len(top) < n {
+			top = append(top, KeyValuePair[K, V]{Key: k, Value: v})
+			for i := len(top) - 1; i > 0 && after(i, (i-1)/2); i = (i - 1) / 2 {
+				top[i], top[(i-1)/2] = top[(i-1)/2], top[i]
+			}
+			continue
+		}
+		if !less(v, top[0].Value) {
+			continue
+		}
+		top[0] = KeyValuePair[K, V]{Key: k, Value: v}
+		for i := 0; ; {
+			c := 2*i + 1
+			if c >= n {
+				break
+			}
+			if c+1 < n && after(c+1, c) {
+				c++
+			}
+			if !after(c, i) {
+				break
+			}
+			top[i], top[c] = top[c], top[i]
+			i = c
+		}
+	}
+	sort.Slice(top, func(i, j int) bool { return less(top[i].Value, top[j].Value) })
+	return top
+}
+
+// MinBy returns the entry with the smallest value according to less in a
+// single pass. It returns false for an empty map. Ties are broken arbitrarily.
+func (m Mapper[K, V]) MinBy(less func(a, b V) bool) (KeyValuePair[K, V], bool) {
+	var best KeyValuePair[K, V]
+	found := false
+	for k, v := range m {
+		if !found || less(v, best.Value) {
+			best = KeyValuePair[K, V]{Key: k, Value: v}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// MaxBy returns the entry with the largest value according to less, e.g. the
+// hottest key of a hit counter. It returns false for an empty map.
+func (m Mapper[K, V]) MaxBy(less func(a, b V) bool) (KeyValuePair[K, V], bool) {
+	return m.MinBy(func(a, b V) bool { return less(b, a) })
+}
+
+// Chunk splits m into mappers of at most size entries, for processing large
+// maps in bounded batches. Keys are distributed in SortedKeys order, so the
+// same map always produces the same chunks. Panics if size is less than 1.
+func (m Mapper[K, V]) Chunk(size int) []Mapper[K, V] {
+	if size < 1 {
+		panic("mappo: chunk size must be at least 1")
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	chunks := make([]Mapper[K, V], 0, (len(m)+size-1)/size)
+	var cur Mapper[K, V]
+	for _, k := range m.SortedKeys() {
+		if len(cur) == size {
+			cur = nil
+		}
+		if cur == nil {
+			cur = NewMapperWithCapacity[K, V](min(size, len(m)-len(chunks)*size))
+			chunks = append(chunks, cur)
+		}
+		cur[k] = m[k]
+	}
+	return chunks
+}
+
+// Sample returns up to n entries chosen uniformly at random, using reservoir
+// sampling so every entry has the same chance regardless of iteration order.
+// When n >= Len all entries are returned, in random order.
+func (m Mapper[K, V]) Sample(n int) []KeyValuePair[K, V] {
+	if n <= 0 || len(m) == 0 {
+		return nil
+	}
+	sample := make([]KeyValuePair[K, V], 0, min(n, len(m)))
+	seen := 0
+	for k, v := range m {
+		seen++
+		if len(sample) < n {
+			sample = append(sample, KeyValuePair[K, V]{Key: k, Value: v})
+		} else if j := rand.IntN(seen); j < n {
+			sample[j] = KeyValuePair[K, V]{Key: k, Value: v}
+		}
+	}
+	rand.Shuffle(len(sample), func(i, j int) { sample[i], sample[j] = sample[j], sample[i] })
+	return sample
+}
+
+// SortedKeysOrdered returns the keys of m in ascending order. Apart from the
+// returned slice it does not allocate.
+func SortedKeysOrdered[K cmp.Ordered, V any](m Mapper[K, V]) []K {
+	keys := m.Keys()
+	slices.Sort(keys)
+	return keys
+}
+
+// SortedKeysFunc returns the keys of m sorted by cmp, which follows the
+// slices.SortFunc convention of returning a negative, zero or positive int.
+func SortedKeysFunc[K comparable, V any](m Mapper[K, V], cmp func(a, b K) int) []K {
+	keys := m.Keys()
+	slices.SortFunc(keys, cmp)
+	return keys
+}
+
 // NewBoolMapper creates a Mapper[K, bool] with keys set to true.
 func NewBoolMapper[K comparable](keys ...K) Mapper[K, bool] {
 	if len(keys) == 0 {
@@ -362,6 +607,113 @@ func NewIdentityMapper[K comparable](keys ...K) Mapper[K, K] {
 	return mapper
 }
 
+// GroupBy builds a Mapper from items, grouping those with the same key.
+// Items keep their slice order within a group.
+func GroupBy[T any, K comparable](items []T, key func(T) K) Mapper[K, []T] {
+	mapper := NewMapper[K, []T]()
+	for _, item := range items {
+		k := key(item)
+		mapper[k] = append(mapper[k], item)
+	}
+	return mapper
+}
+
+// KeyBy builds a lookup Mapper from items. When keys collide the last item wins.
+func KeyBy[T any, K comparable](items []T, key func(T) K) Mapper[K, T] {
+	mapper := NewMapperWithCapacity[K, T](len(items))
+	for _, item := range items {
+		mapper[key(item)] = item
+	}
+	return mapper
+}
+
+// CountBy builds a frequency table of the keys derived from items.
+func CountBy[T any, K comparable](items []T, key func(T) K) Mapper[K, int] {
+	result := NewMapper[K, int]()
+	for _, item := range items {
+		result[key(item)]++
+	}
+	return result
+}
+
+// Counts returns how many keys of m hold each value. It is a function rather
+// than a method because Go methods cannot require V to be comparable.
+func Counts[K, V comparable](m Mapper[K, V]) Mapper[V, int] {
+	result := NewMapper[V, int]()
+	for _, v := range m {
+		result[v]++
+	}
+	return result
+}
+
+// EqualValues reports whether a and b hold the same key-value pairs, comparing
+// values with ==. A nil and an empty Mapper are equal.
+func EqualValues[K, V comparable](a, b Mapper[K, V]) bool {
+	return maps.Equal(a, b)
+}
+
+// MapValuesTo returns a new Mapper with values converted by fn,
+// which unlike the MapValues method may change the value type.
+func MapValuesTo[K comparable, V1, V2 any](m Mapper[K, V1], fn func(V1) V2) Mapper[K, V2] {
+	result := NewMapperWithCapacity[K, V2](len(m))
+	for k, v := range m {
+		result[k] = fn(v)
+	}
+	return result
+}
+
+// MapKeysTo returns a new Mapper with keys converted by fn, which unlike the
+// MapKeys method may change the key type. When two keys convert to the same
+// key, resolve picks the value to keep instead of panicking; with a nil
+// resolve an arbitrary one is kept.
+func MapKeysTo[K1, K2 comparable, V any](m Mapper[K1, V], fn func(K1) K2, resolve func(key K2, existing, incoming V) V) Mapper[K2, V] {
+	result := NewMapperWithCapacity[K2, V](len(m))
+	for k, v := range m {
+		newKey := fn(k)
+		if existing, ok := result[newKey]; ok && resolve != nil {
+			v = resolve(newKey, existing, v)
+		}
+		result[newKey] = v
+	}
+	return result
+}
+
+// Zip builds a Mapper pairing keys[i] with values[i]. It returns
+// ErrLengthMismatch if the slices differ in length. When keys repeat the
+// last value wins.
+func Zip[K comparable, V any](keys []K, values []V) (Mapper[K, V], error) {
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("%w: %d keys, %d values", ErrLengthMismatch, len(keys), len(values))
+	}
+	result := NewMapperWithCapacity[K, V](len(keys))
+	for i, k := range keys {
+		result[k] = values[i]
+	}
+	return result, nil
+}
+
+// Unzip splits m into parallel key and value slices, so values[i] belongs to
+// keys[i]. The order is random; use UnzipFunc for a stable one.
+func Unzip[K comparable, V any](m Mapper[K, V]) ([]K, []V) {
+	keys := make([]K, 0, len(m))
+	values := make([]V, 0, len(m))
+	for k, v := range m {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	return keys, values
+}
+
+// UnzipFunc is like Unzip but orders both slices by key using cmp.
+func UnzipFunc[K comparable, V any](m Mapper[K, V], cmp func(a, b K) int) ([]K, []V) {
+	keys := SortedKeysFunc(m, cmp)
+	values := make([]V, len(keys))
+	for i, k := range keys {
+		values[i] = m[k]
+	}
+	return keys, values
+}
+
 // Merge combines multiple mappers (later ones override earlier ones).
 func Merge[K comparable, V any](maps ...Mapper[K, V]) Mapper[K, V] {
 	totalLen := 0
@@ -377,6 +729,21 @@ func Merge[K comparable, V any](maps ...Mapper[K, V]) Mapper[K, V] {
 	return result
 }
 
+// MergeDefaults sets every key of defaults that is absent from dst, leaving
+// existing keys untouched, and returns dst. Unlike Merge it modifies dst;
+// a nil dst is replaced by a new Mapper.
+func MergeDefaults[K comparable, V any](dst, defaults Mapper[K, V]) Mapper[K, V] {
+	if dst == nil {
+		dst = NewMapperWithCapacity[K, V](len(defaults))
+	}
+	for k, v := range defaults {
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+	return dst
+}
+
 // MergeWith combines mappers using a merge function for conflicts.
 func MergeWith[K comparable, V any](mergeFn func(K, V, V) V, maps ...Mapper[K, V]) Mapper[K, V] {
 	if len(maps) == 0 {
@@ -399,6 +766,16 @@ func MergeWith[K comparable, V any](mergeFn func(K, V, V) V, maps ...Mapper[K, V
 	return result
 }
 
+// Reduce folds all entries into an accumulator of any type, starting from init.
+// Map iteration order is random, so fn should not depend on it.
+func Reduce[K comparable, V any, R any](m Mapper[K, V], init R, fn func(acc R, key K, value V) R) R {
+	acc := init
+	for k, v := range m {
+		acc = fn(acc, k, v)
+	}
+	return acc
+}
+
 // Invert swaps keys and values. Panics if values aren't unique.
 func Invert[K comparable, V comparable](m Mapper[K, V]) Mapper[V, K] {
 	if m == nil || len(m) == 0 {
@@ -413,3 +790,29 @@ func Invert[K comparable, V comparable](m Mapper[K, V]) Mapper[V, K] {
 	}
 	return result
 }
+
+// TryInvert swaps keys and values like Invert, but returns ErrDuplicateValue
+// instead of panicking when values aren't unique.
+func TryInvert[K comparable, V comparable](m Mapper[K, V]) (Mapper[V, K], error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	result := make(Mapper[V, K], len(m))
+	for k, v := range m {
+		if _, exists := result[v]; exists {
+			return nil, fmt.Errorf("%w: %v", ErrDuplicateValue, v)
+		}
+		result[v] = k
+	}
+	return result, nil
+}
+
+// InvertMulti swaps keys and values, collecting every key that shares a value.
+// Keys within a group are in map iteration order, which is random.
+func InvertMulti[K comparable, V comparable](m Mapper[K, V]) Mapper[V, []K] {
+	result := make(Mapper[V, []K], len(m))
+	for k, v := range m {
+		result[v] = append(result[v], k)
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package mappo
+
+import "sort"
+
+// ToSharded copies m into a new Sharded map built with cfg.
+// When cfg.SizeHint is zero it is set to Len, so shards are presized.
+func (m Mapper[K, V]) ToSharded(cfg ShardedConfig) *Sharded[K, V] {
+	if cfg.SizeHint == 0 {
+		cfg.SizeHint = len(m)
+	}
+	s := NewShardedWithConfig[K, V](cfg)
+	s.SetMany(m)
+	return s
+}
+
+// ToConcurrent copies m into a new Concurrent map with default configuration.
+func (m Mapper[K, V]) ToConcurrent() *Concurrent[K, V] {
+	c := NewConcurrent[K, V]()
+	c.SetMany(m)
+	return c
+}
+
+// ToOrdered copies m into a new Ordered map, inserting keys in the order
+// given by less. The result is not protected for concurrent use.
+func (m Mapper[K, V]) ToOrdered(less func(a, b K) bool) *Ordered[K, V] {
+	keys := m.Keys()
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	o := NewOrdered[K, V]()
+	for _, k := range keys {
+		o.Set(k, m[k])
+	}
+	return o
+}
+
+// NewMapperFromSharded copies the live entries of s into a new Mapper.
+// Each shard is captured at a single point in time, see Sharded.Snapshot.
+func NewMapperFromSharded[K comparable, V any](s *Sharded[K, V]) Mapper[K, V] {
+	return s.Snapshot()
+}
+
+// NewMapperFromConcurrent copies the live entries of c into a new Mapper.
+// Writes racing with the copy may or may not be included.
+func NewMapperFromConcurrent[K comparable, V any](c *Concurrent[K, V]) Mapper[K, V] {
+	m := NewMapperWithCapacity[K, V](c.Len())
+	c.Range(func(k K, v V) bool {
+		m[k] = v
+		return true
+	})
+	return m
+}
+
+// NewMapperFromOrdered copies o into a new Mapper, dropping the order.
+func NewMapperFromOrdered[K comparable, V any](o *Ordered[K, V]) Mapper[K, V] {
+	m := NewMapperWithCapacity[K, V](o.Len())
+	o.Range(func(k K, v V) bool {
+		m[k] = v
+		return true
+	})
+	return m
+}
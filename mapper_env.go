@@ -0,0 +1,56 @@
+package mappo
+
+import (
+	"os"
+	"strings"
+)
+
+// NewMapperFromEnviron returns the environment variables whose names start
+// with prefix, with the prefix stripped: APP_DB_HOST becomes DB_HOST for the
+// prefix "APP_". An empty prefix returns the whole environment.
+func NewMapperFromEnviron(prefix string) Mapper[string, string] {
+	m := NewMapper[string, string]()
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+		m[name[len(prefix):]] = value
+	}
+	return m
+}
+
+// NestEnviron lowercases env keys and splits them on "_" into nested maps, so
+// DB_HOST becomes {"db": {"host": ...}} and the result can be layered with
+// DeepMerge over configuration read from files. When a key is both a value
+// and a prefix of other keys, as with DB and DB_HOST, the nested map wins.
+// To lowercase without nesting use MapKeysTo(env, strings.ToLower, nil).
+func NestEnviron(env Mapper[string, string]) Mapper[string, any] {
+	names := SortedKeysOrdered(env) // deterministic when keys differ only in case
+	result := NewMapper[string, any]()
+	for _, name := range names {
+		var segs []string
+		for _, seg := range strings.Split(strings.ToLower(name), "_") {
+			if seg != "" {
+				segs = append(segs, seg)
+			}
+		}
+		if len(segs) == 0 {
+			continue
+		}
+		cur := map[string]any(result)
+		for _, seg := range segs[:len(segs)-1] {
+			next, ok := cur[seg].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				cur[seg] = next
+			}
+			cur = next
+		}
+		last := segs[len(segs)-1]
+		if _, nested := cur[last].(map[string]any); !nested {
+			cur[last] = env[name]
+		}
+	}
+	return result
+}
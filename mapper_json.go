@@ -0,0 +1,190 @@
+package mappo
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ErrDuplicateKey is returned by DecodeJSONStrict when an object repeats a key.
+var ErrDuplicateKey = errors.New("mappo: duplicate key")
+
+// DecodeJSON reads one JSON object from r into a new Mapper, decoding a value
+// at a time so the raw document is never held in memory. Keys follow the
+// encoding/json rules for map keys: string kinds, integers, or types that
+// implement encoding.TextUnmarshaler. A JSON null yields a nil Mapper.
+func DecodeJSON[K comparable, V any](r io.Reader) (Mapper[K, V], error) {
+	return decodeJSON[K, V](r, false)
+}
+
+// DecodeJSONStrict is like DecodeJSON but returns ErrDuplicateKey when the
+// object repeats a key, which encoding/json silently resolves to the last value.
+func DecodeJSONStrict[K comparable, V any](r io.Reader) (Mapper[K, V], error) {
+	return decodeJSON[K, V](r, true)
+}
+
+func decodeJSON[K comparable, V any](r io.Reader, strict bool) (Mapper[K, V], error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return nil, nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, fmt.Errorf("mappo: expected JSON object, got %v", tok)
+	}
+	m := NewMapper[K, V]()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name := tok.(string) // object keys are always strings
+		key, err := parseJSONKey[K](name)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := m[key]; dup && strict {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateKey, name)
+		}
+		var v V
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	if _, err := dec.Token(); err != nil { // closing brace
+		return nil, err
+	}
+	return m, nil
+}
+
+// EncodeJSON writes m to w as a JSON object with keys sorted as json.Marshal
+// sorts them, encoding one value at a time. A nil Mapper is written as null.
+func (m Mapper[K, V]) EncodeJSON(w io.Writer) error {
+	if m == nil {
+		_, err := io.WriteString(w, "null")
+		return err
+	}
+	type namedKey struct {
+		name string
+		key  K
+	}
+	keys := make([]namedKey, 0, len(m))
+	for k := range m {
+		name, err := formatJSONKey(k)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, namedKey{name: name, key: k})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].name < keys[j].name })
+
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('{')
+	for i, nk := range keys {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		name, _ := json.Marshal(nk.name) // strings always marshal
+		bw.Write(name)
+		bw.WriteByte(':')
+		value, err := json.Marshal(m[nk.key])
+		if err != nil {
+			return err
+		}
+		bw.Write(value)
+	}
+	bw.WriteByte('}')
+	return bw.Flush()
+}
+
+// parseJSONKey converts an object key to K, checking encoding.TextUnmarshaler
+// before the key kind as encoding/json does.
+func parseJSONKey[K comparable](s string) (K, error) {
+	var k K
+	if tu, ok := any(&k).(encoding.TextUnmarshaler); ok {
+		return k, tu.UnmarshalText([]byte(s))
+	}
+	kv := reflect.ValueOf(&k).Elem()
+	switch kv.Kind() {
+	case reflect.String:
+		kv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, kv.Type().Bits())
+		if err != nil {
+			return k, fmt.Errorf("mappo: invalid key %q: %w", s, err)
+		}
+		kv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, kv.Type().Bits())
+		if err != nil {
+			return k, fmt.Errorf("mappo: invalid key %q: %w", s, err)
+		}
+		kv.SetUint(n)
+	default:
+		return k, fmt.Errorf("mappo: unsupported key type %T", k)
+	}
+	return k, nil
+}
+
+// formatJSONKey converts k to an object key, checking the key kind before
+// encoding.TextMarshaler as encoding/json does.
+func formatJSONKey[K comparable](k K) (string, error) {
+	kv := reflect.ValueOf(&k).Elem()
+	if kv.Kind() == reflect.String {
+		return kv.String(), nil
+	}
+	if tm, ok := any(k).(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	switch kv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(kv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(kv.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("mappo: unsupported key type %T", k)
+}
+
+// Value implements driver.Valuer, storing m as a JSON document so it can be
+// written to json and jsonb columns directly. A nil Mapper is stored as NULL.
+func (m Mapper[K, V]) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(map[K]V(m))
+}
+
+// Scan implements sql.Scanner, decoding a JSON document read from the
+// database. NULL sets m to nil.
+func (m *Mapper[K, V]) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("mappo: cannot scan %T into Mapper", src)
+	}
+	var out map[K]V
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	*m = out
+	return nil
+}
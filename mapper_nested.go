@@ -0,0 +1,291 @@
+package mappo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrMergeConflict is returned by DeepMergeWith under MergeError when two
+// layers hold different non-map values at the same path.
+var ErrMergeConflict = errors.New("mappo: merge conflict")
+
+// ErrInvalidPath is returned by SetPath when a path cannot be followed, e.g.
+// when it crosses a scalar value or indexes past the end of a slice.
+var ErrInvalidPath = errors.New("mappo: invalid path")
+
+// MergeStrategy decides what DeepMergeWith does when two layers set different
+// values at the same path and at least one of them is not a map.
+type MergeStrategy int
+
+const (
+	// MergeOverride lets the later layer win. This is the default.
+	MergeOverride MergeStrategy = iota
+	// MergeKeep keeps the value from the earlier layer.
+	MergeKeep
+	// MergeError aborts the merge with ErrMergeConflict.
+	MergeError
+)
+
+// DeepMergeOptions configures DeepMergeWith.
+type DeepMergeOptions struct {
+	// Strategy resolves conflicting non-map values.
+	Strategy MergeStrategy
+	// AppendSlices concatenates []any values instead of treating them as conflicts.
+	AppendSlices bool
+}
+
+// DeepMerge recursively merges layers of nested maps, later layers overriding
+// earlier ones, e.g. DeepMerge(defaults, file, env, flags). Nested values of type
+// map[string]any or Mapper[string, any] are merged key by key; anything else,
+// including slices, is replaced. The inputs are never modified.
+func DeepMerge(layers ...Mapper[string, any]) Mapper[string, any] {
+	result, _ := DeepMergeWith(DeepMergeOptions{}, layers...)
+	return result
+}
+
+// DeepMergeWith is like DeepMerge but lets opts pick the conflict strategy and
+// slice handling. Nested maps in the result are map[string]any copies.
+func DeepMergeWith(opts DeepMergeOptions, layers ...Mapper[string, any]) (Mapper[string, any], error) {
+	result := NewMapper[string, any]()
+	for _, layer := range layers {
+		if err := deepMergeInto(result, layer, "", opts); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// deepMergeInto merges src into dst, which must be owned by the caller.
+func deepMergeInto(dst, src map[string]any, prefix string, opts DeepMergeOptions) error {
+	for k, v := range src {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		existing, ok := dst[k]
+		if !ok {
+			dst[k] = deepCopyValue(v)
+			continue
+		}
+		if dm, ok := asStringMap(existing); ok {
+			if sm, ok := asStringMap(v); ok {
+				if err := deepMergeInto(dm, sm, path, opts); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if opts.AppendSlices {
+			ds, dok := existing.([]any)
+			ss, sok := v.([]any)
+			if dok && sok {
+				// ds is already a private copy, so it can be extended in place
+				dst[k] = append(ds, deepCopyValue(ss).([]any)...)
+				continue
+			}
+		}
+		switch opts.Strategy {
+		case MergeKeep:
+		case MergeError:
+			if !reflect.DeepEqual(existing, v) {
+				return fmt.Errorf("%w at %q", ErrMergeConflict, path)
+			}
+		default:
+			dst[k] = deepCopyValue(v)
+		}
+	}
+	return nil
+}
+
+// DeepMergeDefaults is MergeDefaults for nested maps: it fills keys missing
+// from dst at any depth, descending into maps present in both, and returns
+// dst. Values taken from defaults are copied so dst never aliases them.
+func DeepMergeDefaults(dst, defaults Mapper[string, any]) Mapper[string, any] {
+	if dst == nil {
+		dst = NewMapper[string, any]()
+	}
+	deepFillDefaults(dst, defaults)
+	return dst
+}
+
+func deepFillDefaults(dst, defaults map[string]any) {
+	for k, v := range defaults {
+		existing, ok := dst[k]
+		if !ok {
+			dst[k] = deepCopyValue(v)
+			continue
+		}
+		dm, dok := asStringMap(existing)
+		sm, sok := asStringMap(v)
+		if dok && sok {
+			deepFillDefaults(dm, sm)
+		}
+	}
+}
+
+// asStringMap reports whether v is a nested map that DeepMerge descends into.
+func asStringMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case map[string]any:
+		return m, true
+	case Mapper[string, any]:
+		return m, true
+	}
+	return nil, false
+}
+
+// deepCopyValue copies nested maps and []any slices so merged results never
+// alias their inputs. Other values are returned as is.
+func deepCopyValue(v any) any {
+	if m, ok := asStringMap(v); ok {
+		out := make(map[string]any, len(m))
+		for k, val := range m {
+			out[k] = deepCopyValue(val)
+		}
+		return out
+	}
+	if s, ok := v.([]any); ok {
+		out := make([]any, len(s))
+		for i, val := range s {
+			out[i] = deepCopyValue(val)
+		}
+		return out
+	}
+	return v
+}
+
+// GetPath returns the value at a dot-separated path such as "db.host".
+// Segments that meet a []any are parsed as indexes, so "items.0.name" reads
+// the name of the first item. Keys containing dots cannot be addressed.
+func GetPath(m Mapper[string, any], path string) (any, bool) {
+	var cur any = map[string]any(m)
+	for _, seg := range strings.Split(path, ".") {
+		var ok bool
+		if cur, ok = pathChild(cur, seg); !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// HasPath reports whether a value exists at path.
+func HasPath(m Mapper[string, any], path string) bool {
+	_, ok := GetPath(m, path)
+	return ok
+}
+
+// SetPath stores value at path, creating missing intermediate maps as
+// map[string]any. Slice elements can be replaced but slices are never grown.
+func SetPath(m Mapper[string, any], path string, value any) error {
+	if m == nil {
+		return fmt.Errorf("%w: nil mapper", ErrInvalidPath)
+	}
+	segs := strings.Split(path, ".")
+	_, err := setPathIn(map[string]any(m), segs, 0, value)
+	return err
+}
+
+// DeletePath removes the value at path and reports whether it existed.
+// Deleting a slice element shifts the remaining elements down.
+func DeletePath(m Mapper[string, any], path string) bool {
+	_, ok := deletePathIn(map[string]any(m), strings.Split(path, "."))
+	return ok
+}
+
+// pathChild returns the child of a nested map or []any named by seg.
+func pathChild(c any, seg string) (any, bool) {
+	if m, ok := asStringMap(c); ok {
+		v, ok := m[seg]
+		return v, ok
+	}
+	if s, ok := c.([]any); ok {
+		if i, ok := sliceIndex(s, seg); ok {
+			return s[i], true
+		}
+	}
+	return nil, false
+}
+
+// sliceIndex parses seg as an in-range index into s.
+func sliceIndex(s []any, seg string) (int, bool) {
+	i, err := strconv.Atoi(seg)
+	if err != nil || i < 0 || i >= len(s) {
+		return 0, false
+	}
+	return i, true
+}
+
+// setPathIn stores value under segs[depth:] within c and returns c.
+func setPathIn(c any, segs []string, depth int, value any) (any, error) {
+	seg := segs[depth]
+	last := depth == len(segs)-1
+	if m, ok := asStringMap(c); ok {
+		if last {
+			m[seg] = value
+			return c, nil
+		}
+		child, ok := m[seg]
+		if !ok || child == nil {
+			child = make(map[string]any)
+		}
+		child, err := setPathIn(child, segs, depth+1, value)
+		if err == nil {
+			m[seg] = child
+		}
+		return c, err
+	}
+	if s, ok := c.([]any); ok {
+		i, ok := sliceIndex(s, seg)
+		if !ok {
+			return c, fmt.Errorf("%w: no index %q at %q", ErrInvalidPath, seg, strings.Join(segs[:depth], "."))
+		}
+		if last {
+			s[i] = value
+			return c, nil
+		}
+		child, err := setPathIn(s[i], segs, depth+1, value)
+		if err == nil {
+			s[i] = child
+		}
+		return c, err
+	}
+	return c, fmt.Errorf("%w: %q is not a map or slice", ErrInvalidPath, strings.Join(segs[:depth], "."))
+}
+
+// deletePathIn removes segs from c, returning the possibly shortened container.
+func deletePathIn(c any, segs []string) (any, bool) {
+	seg := segs[0]
+	if m, ok := asStringMap(c); ok {
+		child, exists := m[seg]
+		if !exists {
+			return c, false
+		}
+		if len(segs) == 1 {
+			delete(m, seg)
+			return c, true
+		}
+		child, ok := deletePathIn(child, segs[1:])
+		if ok {
+			m[seg] = child
+		}
+		return c, ok
+	}
+	if s, ok := c.([]any); ok {
+		i, ok := sliceIndex(s, seg)
+		if !ok {
+			return c, false
+		}
+		if len(segs) == 1 {
+			return append(s[:i:i], s[i+1:]...), true
+		}
+		child, ok := deletePathIn(s[i], segs[1:])
+		if ok {
+			s[i] = child
+		}
+		return c, ok
+	}
+	return c, false
+}
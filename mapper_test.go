@@ -1,6 +1,18 @@
 package mappo
 
-import "testing"
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
 
 func TestMapper_Basic(t *testing.T) {
 	m := NewMapper[string, int]()
@@ -44,6 +56,43 @@ func TestMapper_Filter(t *testing.T) {
 	}
 }
 
+func TestMapper_EverySomeNone(t *testing.T) {
+	m := NewMapperFrom(map[string]string{"host": "localhost", "port": "8080", "user": ""})
+	nonEmpty := func(_ string, v string) bool { return v != "" }
+	if m.Every(nonEmpty) {
+		t.Error("expected Every to fail on empty user")
+	}
+	if !m.Some(nonEmpty) || m.None(nonEmpty) {
+		t.Error("expected some non-empty values")
+	}
+	calls := 0
+	m.Some(func(string, string) bool { calls++; return true })
+	if calls != 1 {
+		t.Errorf("expected Some to stop after first match, got %d calls", calls)
+	}
+	empty := NewMapper[string, string]()
+	if !empty.Every(nonEmpty) || empty.Some(nonEmpty) || !empty.None(nonEmpty) {
+		t.Error("expected vacuous results for empty mapper")
+	}
+}
+
+func TestMapper_DeleteIfMany(t *testing.T) {
+	m := NewMapper[int, int]()
+	for i := 0; i < 10; i++ {
+		m.Set(i, i)
+	}
+	if n := m.DeleteIf(func(_ int, v int) bool { return v%2 == 1 }); n != 5 || m.Len() != 5 {
+		t.Errorf("expected 5 odd values removed, got %d, len %d", n, m.Len())
+	}
+	if n := m.DeleteMany(0, 2, 3, 99); n != 2 || m.Has(0) || m.Has(2) {
+		t.Errorf("expected 2 keys removed, got %d", n)
+	}
+	var nilMap Mapper[int, int]
+	if nilMap.DeleteMany(1) != 0 || nilMap.DeleteIf(func(int, int) bool { return true }) != 0 {
+		t.Error("expected nil mapper to remove nothing")
+	}
+}
+
 func TestMapper_MapValues(t *testing.T) {
 	m := NewMapper[string, int]()
 	m.Set("key", 1)
@@ -53,6 +102,19 @@ func TestMapper_MapValues(t *testing.T) {
 	}
 }
 
+func TestMapper_Apply(t *testing.T) {
+	prices := NewMapperFrom(map[string]float64{"eu": 10, "us": 10})
+	prices.Apply(func(region string, p float64) float64 {
+		if region == "eu" {
+			return p * 1.2
+		}
+		return p
+	})
+	if prices.Get("eu") != 12 || prices.Get("us") != 10 {
+		t.Errorf("expected key-dependent rewrite, got %v", prices)
+	}
+}
+
 func TestMapper_Clone(t *testing.T) {
 	m := NewMapper[string, int]()
 	m.Set("key", 42)
@@ -74,6 +136,21 @@ func TestMapper_ToSlice(t *testing.T) {
 	}
 }
 
+func TestNewMapperFromPairsKeys(t *testing.T) {
+	m := NewMapperFrom(map[string]int{"a": 1, "b": 2})
+	if back := NewMapperFromPairs(m.ToSlice()); !back.Equal(m, func(a, b int) bool { return a == b }) {
+		t.Errorf("expected ToSlice round trip, got %v", back)
+	}
+	dup := NewMapperFromPairs([]KeyValuePair[string, int]{{"a", 1}, {"a", 2}})
+	if dup.Get("a") != 2 {
+		t.Errorf("expected last pair to win, got %d", dup.Get("a"))
+	}
+	lengths := NewMapperFromKeys([]string{"go", "rust"}, func(k string) int { return len(k) })
+	if lengths.Get("go") != 2 || lengths.Get("rust") != 4 {
+		t.Errorf("expected key lengths, got %v", lengths)
+	}
+}
+
 func TestMapper_SortedKeys(t *testing.T) {
 	m := NewMapper[int, string]()
 	m.Set(3, "three")
@@ -85,6 +162,109 @@ func TestMapper_SortedKeys(t *testing.T) {
 	}
 }
 
+func TestSortedKeysOrderedFunc(t *testing.T) {
+	m := NewMapperFrom(map[string]int{"b": 2, "c": 3, "a": 1})
+	if keys := SortedKeysOrdered(m); !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+		t.Errorf("expected [a b c], got %v", keys)
+	}
+	desc := SortedKeysFunc(m, func(a, b string) int { return strings.Compare(b, a) })
+	if !reflect.DeepEqual(desc, []string{"c", "b", "a"}) {
+		t.Errorf("expected [c b a], got %v", desc)
+	}
+	if keys := SortedKeysOrdered(NewMapper[int, int]()); len(keys) != 0 {
+		t.Errorf("expected no keys, got %v", keys)
+	}
+}
+
+func TestSortedByValueTopN(t *testing.T) {
+	m := NewMapper[string, int]()
+	for i := 0; i < 100; i++ {
+		m.Set("k"+strconv.Itoa(i), (i*37)%100)
+	}
+	greater := func(a, b int) bool { return a > b }
+	all := m.SortedByValue(greater)
+	if len(all) != 100 || all[0].Value != 99 || all[99].Value != 0 {
+		t.Fatalf("expected descending values, got first %v last %v", all[0], all[99])
+	}
+	top := m.TopN(5, greater)
+	for i, p := range top {
+		if p.Value != 99-i || m.Get(p.Key) != p.Value {
+			t.Errorf("expected value %d at %d, got %v", 99-i, i, p)
+		}
+	}
+	if len(m.TopN(500, greater)) != 100 {
+		t.Error("expected all entries when n exceeds len")
+	}
+	if m.TopN(0, greater) != nil {
+		t.Error("expected nil for n == 0")
+	}
+}
+
+func TestMinByMaxBy(t *testing.T) {
+	hits := NewMapperFrom(map[string]int{"/": 40, "/login": 7, "/api": 93})
+	less := func(a, b int) bool { return a < b }
+	if p, ok := hits.MaxBy(less); !ok || p.Key != "/api" || p.Value != 93 {
+		t.Errorf("expected /api 93, got %v, %v", p, ok)
+	}
+	if p, ok := hits.MinBy(less); !ok || p.Key != "/login" {
+		t.Errorf("expected /login, got %v, %v", p, ok)
+	}
+	if _, ok := NewMapper[string, int]().MaxBy(less); ok {
+		t.Error("expected false for empty mapper")
+	}
+}
+
+func TestMapper_Chunk(t *testing.T) {
+	m := NewMapper[int, int]()
+	for i := 0; i < 250; i++ {
+		m.Set(i, i*i)
+	}
+	chunks := m.Chunk(100)
+	if len(chunks) != 3 || chunks[0].Len() != 100 || chunks[2].Len() != 50 {
+		t.Fatalf("expected chunks of 100, 100, 50, got %d chunks", len(chunks))
+	}
+	if !chunks[0].Has(0) || !chunks[0].Has(99) || !chunks[2].Has(249) || chunks[1].Get(150) != 22500 {
+		t.Error("expected chunks to follow sorted key order")
+	}
+	if NewMapper[int, int]().Chunk(10) != nil {
+		t.Error("expected nil for empty mapper")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for size 0")
+		}
+	}()
+	m.Chunk(0)
+}
+
+func TestMapper_Sample(t *testing.T) {
+	m := NewMapper[int, int]()
+	for i := 0; i < 10; i++ {
+		m.Set(i, i)
+	}
+	counts := make([]int, 10)
+	for i := 0; i < 10000; i++ {
+		p := m.Sample(1)[0]
+		counts[p.Key]++
+	}
+	for k, c := range counts {
+		if c < 700 || c > 1300 {
+			t.Errorf("expected roughly uniform sampling, key %d picked %d times", k, c)
+		}
+	}
+	sample := m.Sample(4)
+	seen := NewSet[int]()
+	for _, p := range sample {
+		seen.Add(p.Key)
+	}
+	if len(sample) != 4 || seen.Len() != 4 {
+		t.Errorf("expected 4 distinct entries, got %v", sample)
+	}
+	if len(m.Sample(50)) != 10 || m.Sample(0) != nil {
+		t.Error("expected sample size to be capped at Len")
+	}
+}
+
 func TestMapper_Range(t *testing.T) {
 	m := NewMapper[string, int]()
 	m.Set("key1", 1)
@@ -98,6 +278,29 @@ func TestMapper_Range(t *testing.T) {
 	}
 }
 
+func TestMapper_Iterators(t *testing.T) {
+	m := NewMapperFrom(map[string]int{"a": 1, "b": 2, "c": 3})
+	sum := 0
+	for k, v := range m.All() {
+		if m.Get(k) != v {
+			t.Errorf("expected %d for %s, got %d", m.Get(k), k, v)
+		}
+		sum += v
+	}
+	if sum != 6 {
+		t.Errorf("expected sum 6, got %d", sum)
+	}
+	if keys := slices.Sorted(m.KeysSeq()); !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+		t.Errorf("expected [a b c], got %v", keys)
+	}
+	if values := slices.Sorted(m.ValuesSeq()); !reflect.DeepEqual(values, []int{1, 2, 3}) {
+		t.Errorf("expected [1 2 3], got %v", values)
+	}
+	for range m.All() {
+		break // early exit must not panic
+	}
+}
+
 func TestNewBoolMapper(t *testing.T) {
 	m := NewBoolMapper[string]("a", "b")
 	if !m.Get("a") {
@@ -133,6 +336,390 @@ func TestMerge(t *testing.T) {
 	}
 }
 
+func TestDeepMerge(t *testing.T) {
+	defaults := Mapper[string, any]{
+		"port": 8080,
+		"db":   map[string]any{"host": "localhost", "pool": 4},
+		"tags": []any{"a"},
+	}
+	file := Mapper[string, any]{
+		"db":   Mapper[string, any]{"host": "db.internal"},
+		"tags": []any{"b"},
+	}
+	merged := DeepMerge(defaults, file)
+	db := merged["db"].(map[string]any)
+	if db["host"] != "db.internal" || db["pool"] != 4 {
+		t.Errorf("expected nested merge, got %v", db)
+	}
+	if !reflect.DeepEqual(merged["tags"], []any{"b"}) {
+		t.Errorf("expected slices to be replaced, got %v", merged["tags"])
+	}
+	db["pool"] = 8
+	if defaults["db"].(map[string]any)["pool"] != 4 {
+		t.Error("expected inputs to be left untouched")
+	}
+
+	appended, err := DeepMergeWith(DeepMergeOptions{AppendSlices: true}, defaults, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(appended["tags"], []any{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", appended["tags"])
+	}
+
+	kept, _ := DeepMergeWith(DeepMergeOptions{Strategy: MergeKeep}, defaults, file)
+	if kept["db"].(map[string]any)["host"] != "localhost" {
+		t.Errorf("expected earlier value to be kept, got %v", kept["db"])
+	}
+
+	env := Mapper[string, any]{"db": map[string]any{"host": "db.prod"}}
+	_, err = DeepMergeWith(DeepMergeOptions{Strategy: MergeError}, defaults, env)
+	if !errors.Is(err, ErrMergeConflict) || !strings.Contains(err.Error(), `"db.host"`) {
+		t.Errorf("expected conflict at db.host, got %v", err)
+	}
+	if _, err := DeepMergeWith(DeepMergeOptions{Strategy: MergeError}, defaults, defaults); err != nil {
+		t.Errorf("expected equal values not to conflict, got %v", err)
+	}
+}
+
+func TestMergeDefaults(t *testing.T) {
+	opts := NewMapperFrom(map[string]int{"retries": 5})
+	MergeDefaults(opts, Mapper[string, int]{"retries": 3, "timeout": 30})
+	if opts.Get("retries") != 5 || opts.Get("timeout") != 30 {
+		t.Errorf("expected only missing keys to be filled, got %v", opts)
+	}
+	if filled := MergeDefaults(nil, Mapper[string, int]{"a": 1}); filled.Get("a") != 1 {
+		t.Errorf("expected nil dst to be replaced, got %v", filled)
+	}
+
+	cfg := Mapper[string, any]{"db": map[string]any{"host": "db.internal"}}
+	defaults := Mapper[string, any]{
+		"db":   map[string]any{"host": "localhost", "port": 5432},
+		"tags": []any{"a"},
+	}
+	DeepMergeDefaults(cfg, defaults)
+	if v, _ := GetPath(cfg, "db.host"); v != "db.internal" {
+		t.Errorf("expected existing host to be kept, got %v", v)
+	}
+	if v, _ := GetPath(cfg, "db.port"); v != 5432 {
+		t.Errorf("expected nested default port, got %v", v)
+	}
+	cfg["tags"].([]any)[0] = "changed"
+	if defaults["tags"].([]any)[0] != "a" {
+		t.Error("expected defaults to be copied")
+	}
+}
+
+func TestPath(t *testing.T) {
+	m := Mapper[string, any]{
+		"db": map[string]any{"host": "localhost"},
+		"items": []any{
+			map[string]any{"name": "first"},
+			map[string]any{"name": "second"},
+		},
+	}
+	if v, ok := GetPath(m, "db.host"); !ok || v != "localhost" {
+		t.Errorf("expected localhost, got %v, %v", v, ok)
+	}
+	if v, _ := GetPath(m, "items.1.name"); v != "second" {
+		t.Errorf("expected second, got %v", v)
+	}
+	if HasPath(m, "items.2.name") || HasPath(m, "db.host.port") || HasPath(m, "missing") {
+		t.Error("expected missing paths to be absent")
+	}
+
+	if err := SetPath(m, "cache.redis.addr", ":6379"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := GetPath(m, "cache.redis.addr"); v != ":6379" {
+		t.Errorf("expected intermediate maps to be created, got %v", v)
+	}
+	if err := SetPath(m, "items.0.name", "updated"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := GetPath(m, "items.0.name"); v != "updated" {
+		t.Errorf("expected updated, got %v", v)
+	}
+	if err := SetPath(m, "items.5.name", "x"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath for out of range index, got %v", err)
+	}
+	if err := SetPath(m, "db.host.port", 1); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath when crossing a scalar, got %v", err)
+	}
+
+	if !DeletePath(m, "items.0") {
+		t.Fatal("expected slice element to be deleted")
+	}
+	if v, _ := GetPath(m, "items.0.name"); v != "second" {
+		t.Errorf("expected elements to shift down, got %v", v)
+	}
+	if !DeletePath(m, "db.host") || HasPath(m, "db.host") {
+		t.Error("expected db.host to be deleted")
+	}
+	if DeletePath(m, "db.host") {
+		t.Error("expected second delete to report false")
+	}
+}
+
+func TestTryInvertMulti(t *testing.T) {
+	m := NewMapperFrom(map[string]int{"a": 1, "b": 2})
+	inv, err := TryInvert(m)
+	if err != nil || inv.Get(1) != "a" || inv.Get(2) != "b" {
+		t.Errorf("expected inverted mapper, got %v, %v", inv, err)
+	}
+	m.Set("c", 1)
+	if _, err := TryInvert(m); !errors.Is(err, ErrDuplicateValue) {
+		t.Errorf("expected ErrDuplicateValue, got %v", err)
+	}
+	multi := InvertMulti(m)
+	ones := multi.Get(1)
+	sort.Strings(ones)
+	if !reflect.DeepEqual(ones, []string{"a", "c"}) || len(multi.Get(2)) != 1 {
+		t.Errorf("expected grouped keys, got %v", multi)
+	}
+}
+
+func TestZipUnzip(t *testing.T) {
+	m, err := Zip([]string{"a", "b", "c"}, []int{1, 2, 3})
+	if err != nil || m.Len() != 3 || m.Get("b") != 2 {
+		t.Fatalf("expected zipped mapper, got %v, %v", m, err)
+	}
+	if _, err := Zip([]string{"a"}, []int{1, 2}); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("expected ErrLengthMismatch, got %v", err)
+	}
+	keys, values := Unzip(m)
+	for i, k := range keys {
+		if m.Get(k) != values[i] {
+			t.Errorf("expected values[%d] to belong to %s", i, k)
+		}
+	}
+	keys, values = UnzipFunc(m, strings.Compare)
+	if !reflect.DeepEqual(keys, []string{"a", "b", "c"}) || !reflect.DeepEqual(values, []int{1, 2, 3}) {
+		t.Errorf("expected sorted slices, got %v %v", keys, values)
+	}
+}
+
+func TestMapperJSON(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	m, err := DecodeJSON[int, user](strings.NewReader(`{"2": {"name": "bob"}, "1": {"name": "ann"}}`))
+	if err != nil || m.Len() != 2 || m.Get(1).Name != "ann" {
+		t.Fatalf("expected decoded users, got %v, %v", m, err)
+	}
+	var buf strings.Builder
+	if err := m.EncodeJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(map[int]user(m))
+	if buf.String() != string(want) {
+		t.Errorf("expected %s, got %s", want, buf.String())
+	}
+
+	dup := `{"a": 1, "a": 2}`
+	if lax, err := DecodeJSON[string, int](strings.NewReader(dup)); err != nil || lax.Get("a") != 2 {
+		t.Errorf("expected last duplicate to win, got %v, %v", lax, err)
+	}
+	if _, err := DecodeJSONStrict[string, int](strings.NewReader(dup)); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("expected ErrDuplicateKey, got %v", err)
+	}
+	if _, err := DecodeJSON[int, int](strings.NewReader(`{"x": 1}`)); err == nil {
+		t.Error("expected error for non-integer key")
+	}
+	if _, err := DecodeJSON[string, int](strings.NewReader(`[1]`)); err == nil {
+		t.Error("expected error for non-object input")
+	}
+	if null, err := DecodeJSON[string, int](strings.NewReader(`null`)); err != nil || null != nil {
+		t.Errorf("expected nil mapper for null, got %v, %v", null, err)
+	}
+}
+
+func TestMapperSQL(t *testing.T) {
+	var valuer driver.Valuer = Mapper[string, any]{"theme": "dark", "size": 2.0}
+	v, err := valuer.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scanned Mapper[string, any]
+	var scanner sql.Scanner = &scanned
+	if err := scanner.Scan(v); err != nil {
+		t.Fatal(err)
+	}
+	if scanned.Get("theme") != "dark" || scanned.Get("size") != 2.0 {
+		t.Errorf("expected round trip, got %v", scanned)
+	}
+	if err := scanned.Scan(`{"a": 1}`); err != nil || scanned.Len() != 1 {
+		t.Errorf("expected string source to decode, got %v, %v", scanned, err)
+	}
+	if err := scanned.Scan(nil); err != nil || scanned != nil {
+		t.Errorf("expected NULL to reset mapper, got %v, %v", scanned, err)
+	}
+	if err := scanned.Scan(42); err == nil {
+		t.Error("expected error for unsupported source")
+	}
+	if v, _ := Mapper[string, any](nil).Value(); v != nil {
+		t.Errorf("expected NULL for nil mapper, got %v", v)
+	}
+}
+
+func TestMapperValues(t *testing.T) {
+	q, _ := url.ParseQuery("tag=a&tag=b&page=2&ids=1,2,3")
+	m := NewMapperFromValues(q)
+	if !reflect.DeepEqual(m.Get("tag"), []string{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", m.Get("tag"))
+	}
+	m.Get("tag")[0] = "changed"
+	if q.Get("tag") != "a" {
+		t.Error("expected values to be copied")
+	}
+
+	flat := FlattenValues(m)
+	if flat.Get("page") != "2" || flat.Get("tag") != "changed" {
+		t.Errorf("expected first values, got %v", flat)
+	}
+	exploded := ExplodeValues(flat.Filter(func(k, _ string) bool { return k == "ids" }), ",")
+	if !reflect.DeepEqual(exploded.Get("ids"), []string{"1", "2", "3"}) {
+		t.Errorf("expected split ids, got %v", exploded)
+	}
+	if got := ToValues(ExplodeValues(flat, "")).Encode(); got != "ids=1%2C2%2C3&page=2&tag=changed" {
+		t.Errorf("unexpected query string %q", got)
+	}
+}
+
+func TestMapperEnviron(t *testing.T) {
+	t.Setenv("MAPPOTEST_DB_HOST", "db.internal")
+	t.Setenv("MAPPOTEST_DB_PORT", "5432")
+	t.Setenv("MAPPOTEST_DB", "ignored")
+	t.Setenv("MAPPOTEST_DEBUG", "true")
+	env := NewMapperFromEnviron("MAPPOTEST_")
+	if env.Len() != 4 || env.Get("DB_HOST") != "db.internal" {
+		t.Fatalf("expected prefix to be stripped, got %v", env)
+	}
+	nested := NestEnviron(env)
+	if v, _ := GetPath(nested, "db.port"); v != "5432" {
+		t.Errorf("expected db.port, got %v", v)
+	}
+	if _, ok := nested["db"].(map[string]any); !ok {
+		t.Errorf("expected nested map to win over DB, got %v", nested["db"])
+	}
+	cfg := DeepMerge(Mapper[string, any]{"db": map[string]any{"host": "localhost", "pool": 4}}, nested)
+	if v, _ := GetPath(cfg, "db.host"); v != "db.internal" {
+		t.Errorf("expected env to override defaults, got %v", v)
+	}
+	if v, _ := GetPath(cfg, "db.pool"); v != 4 {
+		t.Errorf("expected defaults to be kept, got %v", v)
+	}
+}
+
+func TestMapperConversions(t *testing.T) {
+	m := NewMapperFrom(map[string]int{"b": 2, "a": 1, "c": 3})
+	eq := func(a, b int) bool { return a == b }
+
+	s := m.ToSharded(ShardedConfig{ShardCount: 4})
+	if s.Len() != 3 || !NewMapperFromSharded(s).Equal(m, eq) {
+		t.Errorf("expected sharded round trip, got %v", s.Snapshot())
+	}
+	c := m.ToConcurrent()
+	if v, _ := c.Get("b"); v != 2 || !NewMapperFromConcurrent(c).Equal(m, eq) {
+		t.Error("expected concurrent round trip")
+	}
+	o := m.ToOrdered(func(a, b string) bool { return a < b })
+	if !reflect.DeepEqual(o.Keys(), []string{"a", "b", "c"}) {
+		t.Errorf("expected keys in less order, got %v", o.Keys())
+	}
+	if !NewMapperFromOrdered(o).Equal(m, eq) {
+		t.Error("expected ordered round trip")
+	}
+}
+
+func TestGroupByKeyBy(t *testing.T) {
+	type user struct {
+		id   int
+		team string
+	}
+	users := []user{{1, "red"}, {2, "blue"}, {3, "red"}}
+
+	byTeam := GroupBy(users, func(u user) string { return u.team })
+	if len(byTeam) != 2 || len(byTeam["red"]) != 2 || byTeam["red"][1].id != 3 {
+		t.Errorf("unexpected groups %v", byTeam)
+	}
+
+	byID := KeyBy(users, func(u user) int { return u.id })
+	if byID.Get(2).team != "blue" || byID.Len() != 3 {
+		t.Errorf("unexpected index %v", byID)
+	}
+	lastWins := KeyBy(users, func(u user) string { return u.team })
+	if lastWins.Get("red").id != 3 {
+		t.Errorf("expected last item to win, got %v", lastWins.Get("red"))
+	}
+
+	empty := GroupBy([]user(nil), func(u user) string { return u.team })
+	empty.Set("x", nil) // must be usable
+}
+
+func TestCountBy(t *testing.T) {
+	words := []string{"go", "rust", "zig", "c", "java"}
+	byLen := CountBy(words, func(w string) int { return len(w) })
+	if byLen.Get(4) != 2 || byLen.Get(1) != 1 || byLen.Len() != 4 {
+		t.Errorf("expected length frequencies, got %v", byLen)
+	}
+	status := NewMapperFrom(map[string]string{"a": "up", "b": "down", "c": "up"})
+	if counts := Counts(status); counts.Get("up") != 2 || counts.Get("down") != 1 {
+		t.Errorf("expected value frequencies, got %v", counts)
+	}
+}
+
+func TestEqualValues(t *testing.T) {
+	a := NewMapperFrom(map[string]int{"x": 1, "y": 2})
+	if !EqualValues(a, a.Clone()) {
+		t.Error("expected clone to be equal")
+	}
+	if EqualValues(a, NewMapperFrom(map[string]int{"x": 1, "y": 3})) {
+		t.Error("expected different values to be unequal")
+	}
+	if EqualValues(a, NewMapperFrom(map[string]int{"x": 1})) {
+		t.Error("expected different lengths to be unequal")
+	}
+	if !EqualValues(nil, NewMapper[string, int]()) {
+		t.Error("expected nil and empty to be equal")
+	}
+}
+
+func TestMapValuesKeysTo(t *testing.T) {
+	m := NewMapperFrom(map[string]int{"a": 1, "B": 2, "b": 3})
+
+	labels := MapValuesTo(m, func(v int) string { return strconv.Itoa(v * 10) })
+	if labels.Get("B") != "20" || labels.Len() != 3 {
+		t.Errorf("unexpected values %v", labels)
+	}
+
+	sum := func(_ string, existing, incoming int) int { return existing + incoming }
+	lower := MapKeysTo(m, strings.ToLower, sum)
+	if lower.Len() != 2 || lower.Get("b") != 5 {
+		t.Errorf("expected colliding keys resolved to 5, got %v", lower)
+	}
+
+	lengths := MapKeysTo(m, func(k string) int { return len(k) }, nil)
+	if lengths.Len() != 1 {
+		t.Errorf("expected one key without resolver, got %v", lengths)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	m := NewMapperFrom(map[string]int{"a": 1, "bb": 2, "ccc": 3})
+	sum := Reduce(m, 0, func(acc int, _ string, v int) int { return acc + v })
+	if sum != 6 {
+		t.Errorf("expected 6, got %d", sum)
+	}
+	chars := Reduce(m, int64(0), func(acc int64, k string, _ int) int64 { return acc + int64(len(k)) })
+	if chars != 6 {
+		t.Errorf("expected 6, got %d", chars)
+	}
+	if got := Reduce(NewMapper[string, int](), "init", func(acc string, _ string, _ int) string { return "" }); got != "init" {
+		t.Errorf("expected init for empty mapper, got %q", got)
+	}
+}
+
 func BenchmarkMapper_Set(b *testing.B) {
 	m := NewMapper[int, int]()
 	for i := 0; i < b.N; i++ {
@@ -150,3 +737,20 @@ func BenchmarkMapper_Get(b *testing.B) {
 		m.Get(i)
 	}
 }
+
+func BenchmarkMapper_SortedKeys(b *testing.B) {
+	m := NewMapperWithCapacity[string, int](1000)
+	for i := 0; i < 1000; i++ {
+		m.Set(strconv.Itoa(i), i)
+	}
+	b.Run("Reflect", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m.SortedKeys()
+		}
+	})
+	b.Run("Ordered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			SortedKeysOrdered(m)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package mappo
+
+import (
+	"net/url"
+	"strings"
+)
+
+// NewMapperFromValues creates a Mapper from query parameters or form data.
+// The value slices are copied, so the Mapper can be modified freely.
+func NewMapperFromValues(values url.Values) Mapper[string, []string] {
+	m := make(Mapper[string, []string], len(values))
+	for k, vs := range values {
+		m[k] = append([]string(nil), vs...)
+	}
+	return m
+}
+
+// ToValues converts m back to url.Values, e.g. to build a query string with
+// ToValues(m).Encode(). The value slices are copied.
+func ToValues(m Mapper[string, []string]) url.Values {
+	values := make(url.Values, len(m))
+	for k, vs := range m {
+		values[k] = append([]string(nil), vs...)
+	}
+	return values
+}
+
+// FlattenValues keeps the first value of each key, matching url.Values.Get.
+// Keys without values map to the empty string.
+func FlattenValues(m Mapper[string, []string]) Mapper[string, string] {
+	flat := make(Mapper[string, string], len(m))
+	for k, vs := range m {
+		if len(vs) > 0 {
+			flat[k] = vs[0]
+		} else {
+			flat[k] = ""
+		}
+	}
+	return flat
+}
+
+// ExplodeValues is the inverse of FlattenValues. With a non-empty sep each
+// value is split on it, so "ids=1,2,3" becomes three values for ids.
+func ExplodeValues(m Mapper[string, string], sep string) Mapper[string, []string] {
+	exploded := make(Mapper[string, []string], len(m))
+	for k, v := range m {
+		if sep == "" {
+			exploded[k] = []string{v}
+		} else {
+			exploded[k] = strings.Split(v, sep)
+		}
+	}
+	return exploded
+}
@@ -0,0 +1,128 @@
+package mappo
+
+// MultiSet is a generic bag that counts how many times each element was added,
+// based on Mapper. The zero value is ready to use.
+type MultiSet[T comparable] struct {
+	m     Mapper[T, int]
+	total int
+}
+
+// NewMultiSet creates a MultiSet holding elems, counting repeats.
+func NewMultiSet[T comparable](elems ...T) *MultiSet[T] {
+	s := &MultiSet[T]{m: NewMapper[T, int]()}
+	for _, elem := range elems {
+		s.Add(elem)
+	}
+	return s
+}
+
+// Add adds one occurrence of elem.
+func (s *MultiSet[T]) Add(elem T) {
+	s.AddN(elem, 1)
+}
+
+// AddN adds n occurrences of elem. Non-positive n is ignored.
+func (s *MultiSet[T]) AddN(elem T, n int) {
+	if n <= 0 {
+		return
+	}
+	if s.m == nil {
+		s.m = NewMapper[T, int]()
+	}
+	s.m[elem] += n
+	s.total += n
+}
+
+// Remove removes one occurrence of elem and reports whether there was one.
+func (s *MultiSet[T]) Remove(elem T) bool {
+	return s.RemoveN(elem, 1) == 1
+}
+
+// RemoveN removes up to n occurrences of elem and returns how many were removed.
+func (s *MultiSet[T]) RemoveN(elem T, n int) int {
+	count := s.m[elem]
+	if n <= 0 || count == 0 {
+		return 0
+	}
+	removed := min(n, count)
+	if removed == count {
+		delete(s.m, elem)
+	} else {
+		s.m[elem] = count - removed
+	}
+	s.total -= removed
+	return removed
+}
+
+// Count returns how many times elem occurs.
+func (s *MultiSet[T]) Count(elem T) int {
+	return s.m[elem]
+}
+
+// Has returns true if elem occurs at least once.
+func (s *MultiSet[T]) Has(elem T) bool {
+	return s.m[elem] > 0
+}
+
+// Len returns the number of distinct elements.
+func (s *MultiSet[T]) Len() int {
+	return len(s.m)
+}
+
+// TotalLen returns the number of occurrences of all elements.
+func (s *MultiSet[T]) TotalLen() int {
+	return s.total
+}
+
+// IsEmpty returns true if the multiset has no elements.
+func (s *MultiSet[T]) IsEmpty() bool {
+	return s.total == 0
+}
+
+// Clear removes all elements.
+func (s *MultiSet[T]) Clear() {
+	s.m = NewMapper[T, int]()
+	s.total = 0
+}
+
+// Elements returns the distinct elements.
+func (s *MultiSet[T]) Elements() []T {
+	return s.m.Keys()
+}
+
+// Counts returns a copy of the element counts.
+func (s *MultiSet[T]) Counts() Mapper[T, int] {
+	return s.m.Clone()
+}
+
+// Range iterates over distinct elements with their counts.
+func (s *MultiSet[T]) Range(fn func(elem T, count int)) {
+	for elem, count := range s.m {
+		fn(elem, count)
+	}
+}
+
+// Union returns a new multiset where each element occurs as many times as it
+// does in whichever of s and other has more of it.
+func (s *MultiSet[T]) Union(other *MultiSet[T]) *MultiSet[T] {
+	result := NewMultiSet[T]()
+	for elem, count := range s.m {
+		result.AddN(elem, max(count, other.Count(elem)))
+	}
+	for elem, count := range other.m {
+		if !s.Has(elem) {
+			result.AddN(elem, count)
+		}
+	}
+	return result
+}
+
+// Intersection returns a new multiset where each element occurs as many times
+// as it does in whichever of s and other has fewer of it.
+func (s *MultiSet[T]) Intersection(other *MultiSet[T]) *MultiSet[T] {
+	result := NewMultiSet[T]()
+	for elem, count := range s.m {
+		result.AddN(elem, min(count, other.Count(elem)))
+	}
+	return result
+}
@@ -0,0 +1,39 @@
+package mappo
+
+import "testing"
+
+func TestMultiSet_Basic(t *testing.T) {
+	var s MultiSet[string] // zero value is usable
+	s.Add("a")
+	s.AddN("b", 3)
+	s.AddN("c", 0)
+	if s.Count("b") != 3 || s.Len() != 2 || s.TotalLen() != 4 || s.Has("c") {
+		t.Fatalf("expected a:1 b:3, got %v", s.Counts())
+	}
+	if !s.Remove("b") || s.Count("b") != 2 || s.TotalLen() != 3 {
+		t.Errorf("expected one b removed, got %v", s.Counts())
+	}
+	if n := s.RemoveN("b", 10); n != 2 || s.Has("b") {
+		t.Errorf("expected remaining 2 b removed, got %d", n)
+	}
+	if s.Remove("missing") {
+		t.Error("expected Remove of missing element to report false")
+	}
+	if s.Len() != 1 || s.TotalLen() != 1 {
+		t.Errorf("expected only a left, got %v", s.Counts())
+	}
+}
+
+func TestMultiSet_UnionIntersection(t *testing.T) {
+	a := NewMultiSet("x", "x", "x", "y")
+	b := NewMultiSet("x", "y", "y", "z")
+
+	u := a.Union(b)
+	if u.Count("x") != 3 || u.Count("y") != 2 || u.Count("z") != 1 || u.TotalLen() != 6 {
+		t.Errorf("expected max counts, got %v", u.Counts())
+	}
+	i := a.Intersection(b)
+	if i.Count("x") != 1 || i.Count("y") != 1 || i.Has("z") || i.TotalLen() != 2 {
+		t.Errorf("expected min counts, got %v", i.Counts())
+	}
+}
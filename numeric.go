@@ -0,0 +1,31 @@
+package mappo
+
+// Number is the set of integer and floating-point types usable with Increment.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Computer is implemented by maps offering atomic read-modify-write,
+// such as Concurrent and Sharded.
+type Computer[K comparable, V any] interface {
+	Compute(key K, fn func(current V, exists bool) (newValue V, keep bool)) V
+}
+
+// Increment atomically adds delta to the value at key and returns the new value.
+// Missing keys start at zero. On Concurrent the entry keeps its TTL.
+//
+//	hits := mappo.NewSharded[string, int64]()
+//	mappo.Increment(hits, "/index", int64(1))
+func Increment[M Computer[K, N], K comparable, N Number](m M, key K, delta N) N {
+	return m.Compute(key, func(current N, _ bool) (N, bool) {
+		return current + delta, true
+	})
+}
+
+// Decrement atomically subtracts delta from the value at key and returns the new value.
+// Missing keys start at zero.
+func Decrement[M Computer[K, N], K comparable, N Number](m M, key K, delta N) N {
+	return Increment(m, key, -delta)
+}
@@ -0,0 +1,38 @@
+package mappo
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestIncrement(t *testing.T) {
+	c := NewConcurrent[string, int]()
+	s := NewSharded[string, float64]()
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Increment(c, "hits", 1)
+			Increment(s, "load", 0.5)
+		}()
+	}
+	wg.Wait()
+
+	if v, _ := c.Get("hits"); v != 100 {
+		t.Errorf("expected 100, got %d", v)
+	}
+	if v, _ := s.Get("load"); v != 50 {
+		t.Errorf("expected 50, got %f", v)
+	}
+	if v := Decrement(c, "hits", 30); v != 70 {
+		t.Errorf("expected 70, got %d", v)
+	}
+
+	type score uint32
+	u := NewSharded[int, score]()
+	if v := Increment(u, 1, score(3)); v != 3 {
+		t.Errorf("expected 3, got %d", v)
+	}
+}
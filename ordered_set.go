@@ -0,0 +1,88 @@
+package mappo
+
+// OrderedSet is a generic set that remembers insertion order, based on Ordered.
+// Re-adding an existing element keeps its original position.
+type OrderedSet[T comparable] struct {
+	o *Ordered[T, struct{}]
+}
+
+// NewOrderedSet creates an OrderedSet holding elems in order, without duplicates.
+func NewOrderedSet[T comparable](elems ...T) *OrderedSet[T] {
+	return NewOrderedSetWithConfig(OrderedConfig{}, elems...)
+}
+
+// NewOrderedSetWithConfig creates an OrderedSet with configuration,
+// e.g. OrderedConfig{Concurrent: true} for use from multiple goroutines.
+func NewOrderedSetWithConfig[T comparable](cfg OrderedConfig, elems ...T) *OrderedSet[T] {
+	s := &OrderedSet[T]{o: NewOrderedWithConfig[T, struct{}](cfg)}
+	for _, elem := range elems {
+		s.o.Set(elem, struct{}{})
+	}
+	return s
+}
+
+// Add appends elem unless it is already present.
+func (s *OrderedSet[T]) Add(elem T) {
+	s.o.Set(elem, struct{}{})
+}
+
+// Remove removes an element from the set.
+func (s *OrderedSet[T]) Remove(elem T) {
+	s.o.Delete(elem)
+}
+
+// Has returns true if the element exists.
+func (s *OrderedSet[T]) Has(elem T) bool {
+	return s.o.Has(elem)
+}
+
+// Len returns the number of elements.
+func (s *OrderedSet[T]) Len() int {
+	return s.o.Len()
+}
+
+// IsEmpty returns true if the set has no elements.
+func (s *OrderedSet[T]) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+// Clear removes all elements.
+func (s *OrderedSet[T]) Clear() {
+	s.o.Clear()
+}
+
+// Elements returns all elements in insertion order.
+func (s *OrderedSet[T]) Elements() []T {
+	return s.o.Keys()
+}
+
+// ForEach iterates over elements in insertion order. Return false to stop.
+func (s *OrderedSet[T]) ForEach(fn func(T) bool) {
+	s.o.Range(func(elem T, _ struct{}) bool {
+		return fn(elem)
+	})
+}
+
+// Front returns the oldest element.
+func (s *OrderedSet[T]) Front() (T, bool) {
+	elem, _, ok := s.o.Front()
+	return elem, ok
+}
+
+// Back returns the newest element.
+func (s *OrderedSet[T]) Back() (T, bool) {
+	elem, _, ok := s.o.Back()
+	return elem, ok
+}
+
+// PopFront removes and returns the oldest element.
+func (s *OrderedSet[T]) PopFront() (T, bool) {
+	elem, _, ok := s.o.PopFront()
+	return elem, ok
+}
+
+// PopBack removes and returns the newest element.
+func (s *OrderedSet[T]) PopBack() (T, bool) {
+	elem, _, ok := s.o.PopBack()
+	return elem, ok
+}
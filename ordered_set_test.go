@@ -0,0 +1,42 @@
+package mappo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrderedSet_Basic(t *testing.T) {
+	s := NewOrderedSet("b", "a", "b", "c", "a")
+	if !reflect.DeepEqual(s.Elements(), []string{"b", "a", "c"}) {
+		t.Fatalf("expected dedup in first-seen order, got %v", s.Elements())
+	}
+	s.Add("a") // keeps its position
+	s.Add("d")
+	s.Remove("c")
+	if !reflect.DeepEqual(s.Elements(), []string{"b", "a", "d"}) {
+		t.Errorf("expected [b a d], got %v", s.Elements())
+	}
+
+	var seen []string
+	s.ForEach(func(elem string) bool {
+		seen = append(seen, elem)
+		return len(seen) < 2
+	})
+	if !reflect.DeepEqual(seen, []string{"b", "a"}) {
+		t.Errorf("expected ForEach to stop after two, got %v", seen)
+	}
+
+	if front, ok := s.PopFront(); !ok || front != "b" {
+		t.Errorf("expected b from front, got %q, %v", front, ok)
+	}
+	if back, ok := s.PopBack(); !ok || back != "d" {
+		t.Errorf("expected d from back, got %q, %v", back, ok)
+	}
+	if s.Len() != 1 || !s.Has("a") {
+		t.Errorf("expected only a left, got %v", s.Elements())
+	}
+	s.Clear()
+	if _, ok := s.PopFront(); ok || !s.IsEmpty() {
+		t.Error("expected empty set")
+	}
+}
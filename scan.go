@@ -0,0 +1,110 @@
+package mappo
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// keysWithPrefix collects keys from rangeFn whose string form starts with prefix.
+// Keys that are not strings (or string-kinded types) never match.
+func keysWithPrefix[K comparable, V any](rangeFn func(func(K, V) bool), prefix string) []K {
+	if reflect.TypeFor[K]().Kind() != reflect.String {
+		return nil
+	}
+	var keys []K
+	rangeFn(func(k K, _ V) bool {
+		if strings.HasPrefix(keyString(k), prefix) {
+			keys = append(keys, k)
+		}
+		return true
+	})
+	return keys
+}
+
+// scanKeys calls fn for pairs from rangeFn whose string key satisfies match,
+// until fn returns false. Keys that are not string-kinded never match.
+func scanKeys[K comparable, V any](rangeFn func(func(K, V) bool), match func(string) bool, fn func(K, V) bool) {
+	if reflect.TypeFor[K]().Kind() != reflect.String {
+		return
+	}
+	rangeFn(func(k K, v V) bool {
+		if match(keyString(k)) {
+			return fn(k, v)
+		}
+		return true
+	})
+}
+
+// keyString converts a string-kinded key to a string.
+func keyString[K comparable](k K) string {
+	if s, ok := any(k).(string); ok {
+		return s
+	}
+	return reflect.ValueOf(k).String()
+}
+
+// findAll collects pairs from rangeFn matching pred.
+func findAll[K comparable, V any](rangeFn func(func(K, V) bool), pred func(K, V) bool) map[K]V {
+	found := make(map[K]V)
+	rangeFn(func(k K, v V) bool {
+		if pred(k, v) {
+			found[k] = v
+		}
+		return true
+	})
+	return found
+}
+
+// rangeCtx runs rangeFn, stopping early once ctx is done.
+// Returns ctx.Err() if the scan was cancelled, nil otherwise.
+func rangeCtx[K comparable, V any](ctx context.Context, rangeFn func(func(K, V) bool), fn func(K, V) bool) error {
+	var err error
+	rangeFn(func(k K, v V) bool {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return false
+		default:
+		}
+		return fn(k, v)
+	})
+	return err
+}
+
+// transformKeys calls apply for every key using up to parallelism workers
+// and returns how many calls reported a rewrite.
+func transformKeys[K comparable](keys []K, parallelism int, apply func(K) bool) int {
+	if parallelism <= 1 || len(keys) <= 1 {
+		n := 0
+		for _, k := range keys {
+			if apply(k) {
+				n++
+			}
+		}
+		return n
+	}
+
+	var n atomic.Int64
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(parallelism, len(keys)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(keys) {
+					return
+				}
+				if apply(keys[i]) {
+					n.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return int(n.Load())
+}
@@ -1,5 +1,14 @@
 package mappo
 
+import (
+	"encoding/json"
+	"hash/maphash"
+	"iter"
+	"maps"
+	"math/bits"
+	"sort"
+)
+
 // Set is a generic set type based on Mapper.
 type Set[T comparable] struct {
 	m Mapper[T, struct{}]
@@ -14,6 +23,33 @@ func NewSet[T comparable](elems ...T) *Set[T] {
 	return s
 }
 
+// NewSetFromSeq creates a Set from the values of an iterator.
+func NewSetFromSeq[T comparable](seq iter.Seq[T]) *Set[T] {
+	s := NewSet[T]()
+	for elem := range seq {
+		s.m[elem] = struct{}{}
+	}
+	return s
+}
+
+// NewSetFromKeys creates a Set holding the keys of m.
+func NewSetFromKeys[T comparable, V any](m Mapper[T, V]) *Set[T] {
+	s := &Set[T]{m: NewMapperWithCapacity[T, struct{}](len(m))}
+	for k := range m {
+		s.m[k] = struct{}{}
+	}
+	return s
+}
+
+// CollectSet creates a Set of fn applied to each item, e.g. the IDs of a slice of users.
+func CollectSet[S any, T comparable](items []S, fn func(S) T) *Set[T] {
+	s := &Set[T]{m: NewMapperWithCapacity[T, struct{}](len(items))}
+	for _, item := range items {
+		s.m[fn(item)] = struct{}{}
+	}
+	return s
+}
+
 // Add adds an element to the set.
 func (s *Set[T]) Add(elem T) {
 	if s.m == nil {
@@ -22,6 +58,16 @@ func (s *Set[T]) Add(elem T) {
 	s.m[elem] = struct{}{}
 }
 
+// AddMany adds every element of elems.
+func (s *Set[T]) AddMany(elems []T) {
+	if s.m == nil {
+		s.m = NewMapperWithCapacity[T, struct{}](len(elems))
+	}
+	for _, elem := range elems {
+		s.m[elem] = struct{}{}
+	}
+}
+
 // Remove removes an element from the set.
 func (s *Set[T]) Remove(elem T) {
 	if s.m == nil {
@@ -30,6 +76,13 @@ func (s *Set[T]) Remove(elem T) {
 	delete(s.m, elem)
 }
 
+// RemoveMany removes every element of elems.
+func (s *Set[T]) RemoveMany(elems []T) {
+	for _, elem := range elems {
+		delete(s.m, elem)
+	}
+}
+
 // Has returns true if the element exists.
 func (s *Set[T]) Has(elem T) bool {
 	if s.m == nil {
@@ -39,6 +92,26 @@ func (s *Set[T]) Has(elem T) bool {
 	return exists
 }
 
+// HasAll returns true if every one of elems exists. It is true for no elems.
+func (s *Set[T]) HasAll(elems ...T) bool {
+	for _, elem := range elems {
+		if !s.Has(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny returns true if at least one of elems exists.
+func (s *Set[T]) HasAny(elems ...T) bool {
+	for _, elem := range elems {
+		if s.Has(elem) {
+			return true
+		}
+	}
+	return false
+}
+
 // Len returns the number of elements.
 func (s *Set[T]) Len() int {
 	if s.m == nil {
@@ -71,6 +144,50 @@ func (s *Set[T]) Pop() (T, bool) {
 	return zero, false
 }
 
+// PopN removes and returns up to n arbitrary elements.
+func (s *Set[T]) PopN(n int) []T {
+	if n <= 0 || len(s.m) == 0 {
+		return nil
+	}
+	elems := make([]T, 0, min(n, len(s.m)))
+	for elem := range s.m {
+		if len(elems) == n {
+			break
+		}
+		delete(s.m, elem)
+		elems = append(elems, elem)
+	}
+	return elems
+}
+
+// Drain returns an iterator that removes each element just before yielding
+// it. Stopping early leaves the remaining elements in the set, so a set can
+// serve as a pool of pending work. Elements added during the loop may or may
+// not be yielded.
+func (s *Set[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for elem := range s.m {
+			delete(s.m, elem)
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// Sample returns up to n elements chosen uniformly at random.
+func (s *Set[T]) Sample(n int) []T {
+	pairs := s.m.Sample(n)
+	if pairs == nil {
+		return nil
+	}
+	elems := make([]T, len(pairs))
+	for i, p := range pairs {
+		elems[i] = p.Key
+	}
+	return elems
+}
+
 // Elements returns all elements as a slice.
 func (s *Set[T]) Elements() []T {
 	if s.m == nil {
@@ -83,6 +200,13 @@ func (s *Set[T]) Elements() []T {
 	return elems
 }
 
+// ToSortedSlice returns all elements ordered by less.
+func (s *Set[T]) ToSortedSlice(less func(a, b T) bool) []T {
+	elems := s.Elements()
+	sort.Slice(elems, func(i, j int) bool { return less(elems[i], elems[j]) })
+	return elems
+}
+
 // Range iterates over all elements.
 func (s *Set[T]) Range(fn func(T)) {
 	if s.m == nil {
@@ -93,6 +217,85 @@ func (s *Set[T]) Range(fn func(T)) {
 	}
 }
 
+// All returns an iterator over all elements in random order.
+func (s *Set[T]) All() iter.Seq[T] {
+	return maps.Keys(s.m)
+}
+
+// SortedAll returns an iterator over all elements ordered by less.
+// Elements are collected and sorted when iteration starts.
+func (s *Set[T]) SortedAll(less func(a, b T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, elem := range s.ToSortedSlice(less) {
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// PowerSet returns an iterator over all 2^Len subsets, starting with the
+// empty one. Subsets are generated lazily as new slices, in an order that
+// depends on the random order of Elements. Panics if the set has more than
+// 63 elements; in practice it is only useful for small sets.
+func (s *Set[T]) PowerSet() iter.Seq[[]T] {
+	elems := s.Elements()
+	if len(elems) > 63 {
+		panic("mappo: set too large for PowerSet")
+	}
+	return func(yield func([]T) bool) {
+		for mask := uint64(0); mask < 1<<len(elems); mask++ {
+			subset := make([]T, 0, bits.OnesCount64(mask))
+			for i, elem := range elems {
+				if mask&(1<<i) != 0 {
+					subset = append(subset, elem)
+				}
+			}
+			if !yield(subset) {
+				return
+			}
+		}
+	}
+}
+
+// Combinations returns an iterator over all subsets of exactly k elements,
+// generated lazily as new slices. It yields nothing when k is negative or
+// larger than Len, and a single empty slice when k is 0.
+func (s *Set[T]) Combinations(k int) iter.Seq[[]T] {
+	elems := s.Elements()
+	return func(yield func([]T) bool) {
+		n := len(elems)
+		if k < 0 || k > n {
+			return
+		}
+		idx := make([]int, k)
+		for i := range idx {
+			idx[i] = i
+		}
+		for {
+			combo := make([]T, k)
+			for i, j := range idx {
+				combo[i] = elems[j]
+			}
+			if !yield(combo) {
+				return
+			}
+			// Advance the rightmost index that still has room
+			i := k - 1
+			for i >= 0 && idx[i] == n-k+i {
+				i--
+			}
+			if i < 0 {
+				return
+			}
+			idx[i]++
+			for j := i + 1; j < k; j++ {
+				idx[j] = idx[j-1] + 1
+			}
+		}
+	}
+}
+
 // Filter returns a new set with elements satisfying the predicate.
 func (s *Set[T]) Filter(fn func(T) bool) *Set[T] {
 	result := NewSet[T]()
@@ -104,6 +307,19 @@ func (s *Set[T]) Filter(fn func(T) bool) *Set[T] {
 	return result
 }
 
+// Partition splits s in one pass into the elements satisfying pred and the rest.
+func (s *Set[T]) Partition(pred func(T) bool) (matched, rest *Set[T]) {
+	matched, rest = NewSet[T](), NewSet[T]()
+	for elem := range s.m {
+		if pred(elem) {
+			matched.m[elem] = struct{}{}
+		} else {
+			rest.m[elem] = struct{}{}
+		}
+	}
+	return matched, rest
+}
+
 // Clone returns a shallow copy of the set.
 func (s *Set[T]) Clone() *Set[T] {
 	result := NewSet[T]()
@@ -144,6 +360,64 @@ func (s *Set[T]) IsSuperset(other *Set[T]) bool {
 	return other.IsSubset(s)
 }
 
+// ContainsSet returns true if every element of other is in s,
+// e.g. whether granted scopes cover the requested ones. Same as IsSuperset.
+func (s *Set[T]) ContainsSet(other *Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+// IsDisjoint returns true if s and other share no elements.
+// It scans the smaller set and allocates nothing.
+func (s *Set[T]) IsDisjoint(other *Set[T]) bool {
+	small, large := s, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	for elem := range small.m {
+		if large.Has(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// OverlapCount returns the size of the intersection of s and other without
+// building it.
+func (s *Set[T]) OverlapCount(other *Set[T]) int {
+	small, large := s, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	n := 0
+	for elem := range small.m {
+		if large.Has(elem) {
+			n++
+		}
+	}
+	return n
+}
+
+// fingerprintSeed is passed to hashers that use a maphash seed.
+var fingerprintSeed = maphash.MakeSeed()
+
+// Fingerprint returns an order-independent hash of the elements: the sum of
+// their mixed hashes, so equal sets produce the same value whenever hasher
+// hashes equal elements equally. A nil hasher means SeededHasher(0), which
+// is stable across processes but only accepts string, integer and bool
+// element types and panics for others; pass a by-value hasher such as
+// SeededHasherFunc for those. maphash-based hashers such as DefaultHasher
+// are only stable within one process.
+func (s *Set[T]) Fingerprint(hasher Hasher[T]) uint64 {
+	if hasher == nil {
+		hasher = SeededHasher[T](0)
+	}
+	var sum uint64
+	for elem := range s.m {
+		sum += mix64(hasher(elem, fingerprintSeed))
+	}
+	return sum
+}
+
 // Union returns a new set with elements from both sets.
 func (s *Set[T]) Union(other *Set[T]) *Set[T] {
 	result := NewSet[T]()
@@ -214,3 +488,70 @@ func (s *Set[T]) SymmetricDifference(other *Set[T]) *Set[T] {
 	}
 	return result
 }
+
+// MarshalJSON implements json.Marshaler, encoding the set as a JSON array.
+// Elements are sorted as by Mapper.SortedKeys so the output is deterministic.
+// A nil set encodes as null. Like every Set method it has a pointer receiver,
+// so a Set stored by value in a struct is only encoded as an array when the
+// struct is marshaled through a pointer; use a *Set field otherwise.
+func (s *Set[T]) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+	elems := s.m.SortedKeys()
+	if elems == nil {
+		elems = []T{}
+	}
+	return json.Marshal(elems)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the contents of the
+// set with the elements of a JSON array. Duplicates are collapsed.
+func (s *Set[T]) UnmarshalJSON(b []byte) error {
+	var elems []T
+	if err := json.Unmarshal(b, &elems); err != nil {
+		return err
+	}
+	s.m = make(Mapper[T, struct{}], len(elems))
+	for _, elem := range elems {
+		s.m[elem] = struct{}{}
+	}
+	return nil
+}
+
+// MapSet returns a new set of fn applied to each element of s, which may
+// change the element type. Elements mapping to the same value collapse.
+func MapSet[T, U comparable](s *Set[T], fn func(T) U) *Set[U] {
+	result := &Set[U]{m: NewMapperWithCapacity[U, struct{}](s.Len())}
+	for elem := range s.m {
+		result.m[fn(elem)] = struct{}{}
+	}
+	return result
+}
+
+// FlatMapSet returns a new set of all values produced by fn for the elements
+// of s, e.g. every permission granted by a set of roles.
+func FlatMapSet[T, U comparable](s *Set[T], fn func(T) []U) *Set[U] {
+	result := NewSet[U]()
+	for elem := range s.m {
+		for _, u := range fn(elem) {
+			result.m[u] = struct{}{}
+		}
+	}
+	return result
+}
+
+// GroupBySet splits s into sets of elements sharing the same key.
+func GroupBySet[T, K comparable](s *Set[T], key func(T) K) Mapper[K, *Set[T]] {
+	groups := NewMapper[K, *Set[T]]()
+	for elem := range s.m {
+		k := key(elem)
+		g, ok := groups[k]
+		if !ok {
+			g = NewSet[T]()
+			groups[k] = g
+		}
+		g.m[elem] = struct{}{}
+	}
+	return groups
+}
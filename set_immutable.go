@@ -0,0 +1,98 @@
+package mappo
+
+import (
+	"iter"
+	"maps"
+)
+
+// ImmutableSet is a read-only set created by Set.Freeze. It has no mutation
+// methods and owns a private copy of its elements, so it is safe for
+// unlimited concurrent reads without locking, e.g. for shared allow-lists.
+// The zero value is an empty set.
+type ImmutableSet[T comparable] struct {
+	m map[T]struct{}
+}
+
+// Freeze returns an ImmutableSet holding a copy of the current elements.
+// Later changes to s do not affect it.
+func (s *Set[T]) Freeze() ImmutableSet[T] {
+	return ImmutableSet[T]{m: maps.Clone(map[T]struct{}(s.m))}
+}
+
+// Has returns true if the element exists.
+func (s ImmutableSet[T]) Has(elem T) bool {
+	_, ok := s.m[elem]
+	return ok
+}
+
+// HasAll returns true if every one of elems exists.
+func (s ImmutableSet[T]) HasAll(elems ...T) bool {
+	for _, elem := range elems {
+		if !s.Has(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny returns true if at least one of elems exists.
+func (s ImmutableSet[T]) HasAny(elems ...T) bool {
+	for _, elem := range elems {
+		if s.Has(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsSet returns true if every element of other is in s.
+func (s ImmutableSet[T]) ContainsSet(other *Set[T]) bool {
+	if other.Len() > len(s.m) {
+		return false
+	}
+	for elem := range other.m {
+		if !s.Has(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// Len returns the number of elements.
+func (s ImmutableSet[T]) Len() int {
+	return len(s.m)
+}
+
+// IsEmpty returns true if the set has no elements.
+func (s ImmutableSet[T]) IsEmpty() bool {
+	return len(s.m) == 0
+}
+
+// Elements returns all elements as a new slice.
+func (s ImmutableSet[T]) Elements() []T {
+	if len(s.m) == 0 {
+		return nil
+	}
+	elems := make([]T, 0, len(s.m))
+	for elem := range s.m {
+		elems = append(elems, elem)
+	}
+	return elems
+}
+
+// Range iterates over all elements.
+func (s ImmutableSet[T]) Range(fn func(T)) {
+	for elem := range s.m {
+		fn(elem)
+	}
+}
+
+// All returns an iterator over all elements in random order.
+func (s ImmutableSet[T]) All() iter.Seq[T] {
+	return maps.Keys(s.m)
+}
+
+// Thaw returns a mutable Set holding a copy of the elements.
+func (s ImmutableSet[T]) Thaw() *Set[T] {
+	return &Set[T]{m: Mapper[T, struct{}](maps.Clone(s.m))}
+}
@@ -1,6 +1,12 @@
 package mappo
 
-import "testing"
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
 
 func TestSet_Basic(t *testing.T) {
 	s := NewSet[int](1, 2, 3)
@@ -71,6 +77,283 @@ func TestSet_Clear(t *testing.T) {
 	}
 }
 
+func TestSet_Sample(t *testing.T) {
+	s := NewSet[int](1, 2, 3, 4, 5)
+	sample := s.Sample(3)
+	if len(sample) != 3 {
+		t.Fatalf("expected 3 elements, got %v", sample)
+	}
+	for _, v := range sample {
+		if !s.Has(v) {
+			t.Errorf("expected sampled element %d to be in set", v)
+		}
+	}
+	if NewSet[int]().Sample(3) != nil {
+		t.Error("expected nil for empty set")
+	}
+}
+
+func TestSet_JSON(t *testing.T) {
+	type payload struct {
+		Scopes *Set[string] `json:"scopes"`
+		IDs    Set[int]     `json:"ids"`
+	}
+	in := &payload{Scopes: NewSet("write", "read", "admin"), IDs: *NewSet(3, 1, 2)}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"scopes":["admin","read","write"],"ids":[1,2,3]}`; string(b) != want {
+		t.Errorf("expected %s, got %s", want, b)
+	}
+	if b, _ := json.Marshal(&payload{}); string(b) != `{"scopes":null,"ids":[]}` {
+		t.Errorf("expected null and empty array for zero sets, got %s", b)
+	}
+	var nilSet *Set[int]
+	if b, err := nilSet.MarshalJSON(); err != nil || string(b) != "null" {
+		t.Errorf("expected null for a nil set, got %s", b)
+	}
+
+	var out payload
+	if err := json.Unmarshal([]byte(`{"scopes":["read","read","write"],"ids":[]}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Scopes.Len() != 2 || !out.Scopes.Has("write") || out.IDs.Len() != 0 {
+		t.Errorf("expected decoded sets, got %v and %v", out.Scopes.Elements(), out.IDs.Elements())
+	}
+	if b, _ := json.Marshal(NewSet[int]()); string(b) != "[]" {
+		t.Errorf("expected empty array, got %s", b)
+	}
+}
+
+func TestSet_Bulk(t *testing.T) {
+	var allowed Set[string]
+	allowed.AddMany([]string{"read", "write", "admin", "audit"})
+	allowed.RemoveMany([]string{"admin", "missing"})
+	if allowed.Len() != 3 || allowed.Has("admin") {
+		t.Fatalf("expected read, write, audit, got %v", allowed.Elements())
+	}
+	if !allowed.HasAll("read", "write") || allowed.HasAll("read", "admin") || !allowed.HasAll() {
+		t.Error("unexpected HasAll result")
+	}
+	if !allowed.HasAny("admin", "audit") || allowed.HasAny("admin") || allowed.HasAny() {
+		t.Error("unexpected HasAny result")
+	}
+	if !allowed.ContainsSet(NewSet("read", "audit")) || allowed.ContainsSet(NewSet("read", "admin")) {
+		t.Error("unexpected ContainsSet result")
+	}
+}
+
+func TestSet_Iterators(t *testing.T) {
+	s := NewSet(3, 1, 2)
+	sum := 0
+	for v := range s.All() {
+		sum += v
+	}
+	if sum != 6 {
+		t.Errorf("expected sum 6, got %d", sum)
+	}
+	var got []int
+	for v := range s.SortedAll(func(a, b int) bool { return a > b }) {
+		got = append(got, v)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if len(got) != 2 || got[0] != 3 || got[1] != 2 {
+		t.Errorf("expected [3 2], got %v", got)
+	}
+}
+
+func TestSet_Constructors(t *testing.T) {
+	less := func(a, b string) bool { return a < b }
+	fromSeq := NewSetFromSeq(slices.Values([]string{"b", "a", "b"}))
+	if got := fromSeq.ToSortedSlice(less); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", got)
+	}
+	fromKeys := NewSetFromKeys(NewMapperFrom(map[string]int{"x": 1, "y": 2}))
+	if !fromKeys.HasAll("x", "y") || fromKeys.Len() != 2 {
+		t.Errorf("expected keys x and y, got %v", fromKeys.Elements())
+	}
+	type user struct{ team string }
+	teams := CollectSet([]user{{"red"}, {"blue"}, {"red"}}, func(u user) string { return u.team })
+	if got := teams.ToSortedSlice(less); !slices.Equal(got, []string{"blue", "red"}) {
+		t.Errorf("expected [blue red], got %v", got)
+	}
+}
+
+func TestMapSet(t *testing.T) {
+	words := NewSet("go", "rust", "zig", "java")
+	lengths := MapSet(words, func(w string) int { return len(w) })
+	if lengths.Len() != 3 || !lengths.HasAll(2, 3, 4) {
+		t.Errorf("expected lengths 2, 3, 4, got %v", lengths.Elements())
+	}
+	roles := map[string][]string{"admin": {"read", "write"}, "viewer": {"read"}}
+	perms := FlatMapSet(NewSet("admin", "viewer"), func(r string) []string { return roles[r] })
+	if perms.Len() != 2 || !perms.HasAll("read", "write") {
+		t.Errorf("expected read and write, got %v", perms.Elements())
+	}
+}
+
+func TestSet_PartitionGroup(t *testing.T) {
+	s := NewSet(1, 2, 3, 4, 5)
+	even, odd := s.Partition(func(v int) bool { return v%2 == 0 })
+	if even.Len() != 2 || !even.HasAll(2, 4) || odd.Len() != 3 || !odd.HasAll(1, 3, 5) {
+		t.Errorf("expected evens and odds, got %v and %v", even.Elements(), odd.Elements())
+	}
+	groups := GroupBySet(s, func(v int) int { return v % 3 })
+	if groups.Len() != 3 || !groups.Get(1).HasAll(1, 4) || groups.Get(0).Len() != 1 {
+		t.Errorf("unexpected groups %v", groups)
+	}
+}
+
+func TestSet_Freeze(t *testing.T) {
+	s := NewSet("read", "write")
+	frozen := s.Freeze()
+	s.Add("admin")
+	if frozen.Len() != 2 || frozen.Has("admin") {
+		t.Errorf("expected frozen copy to ignore later adds, got %v", frozen.Elements())
+	}
+	if !frozen.HasAll("read", "write") || !frozen.ContainsSet(NewSet("read")) || frozen.ContainsSet(s) {
+		t.Error("unexpected membership results")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				frozen.Has("read")
+				for range frozen.All() {
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	thawed := frozen.Thaw()
+	thawed.Add("audit")
+	if frozen.Has("audit") || thawed.Len() != 3 {
+		t.Error("expected Thaw to return an independent copy")
+	}
+	var zero ImmutableSet[string]
+	if !zero.IsEmpty() || zero.Has("x") {
+		t.Error("expected zero value to be empty")
+	}
+}
+
+func TestSet_DisjointOverlap(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(3, 4, 5)
+	if a.IsDisjoint(b) || a.OverlapCount(b) != 2 || b.OverlapCount(a) != 2 {
+		t.Errorf("expected overlap of 2, got %d", a.OverlapCount(b))
+	}
+	if !a.IsDisjoint(NewSet(9)) || a.OverlapCount(NewSet[int]()) != 0 {
+		t.Error("expected disjoint sets")
+	}
+}
+
+func TestSet_Fingerprint(t *testing.T) {
+	a := NewSet("read", "write", "admin")
+	b := NewSet("admin", "read", "write")
+	if a.Fingerprint(nil) != b.Fingerprint(nil) {
+		t.Error("expected equal sets to share a fingerprint")
+	}
+	if a.Fingerprint(nil) == NewSet("read", "write").Fingerprint(nil) {
+		t.Error("expected different sets to differ")
+	}
+	seeded := SeededHasher[string](7)
+	if a.Fingerprint(seeded) == a.Fingerprint(nil) {
+		t.Error("expected the seed to change the fingerprint")
+	}
+	if a.Fingerprint(DefaultHasher[string]()) != b.Fingerprint(DefaultHasher[string]()) {
+		t.Error("expected DefaultHasher fingerprints to match within a process")
+	}
+	// Golden value: must not change between releases or processes
+	if got := NewSet("read", "write").Fingerprint(nil); got != 0x6768db8bd61662a8 {
+		t.Errorf("expected stable fingerprint 0x6768db8bd61662a8, got %#x", got)
+	}
+	if NewSet[string]().Fingerprint(nil) != 0 {
+		t.Error("expected zero for the empty set")
+	}
+
+	// Structs with string fields need a by-value hasher
+	type grant struct{ role, scope string }
+	byValue := SeededHasherFunc(0, func(dst []byte, g grant) []byte {
+		dst = append(dst, g.role...)
+		return append(append(dst, 0), g.scope...)
+	})
+	x := NewSet(grant{"admin", "org"}, grant{"read", "repo"})
+	y := NewSet(grant{"read", string([]byte("repo"))}, grant{"admin", "org"}) // fresh string header
+	if x.Fingerprint(byValue) != y.Fingerprint(byValue) {
+		t.Error("expected equal struct sets to share a fingerprint")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected nil hasher to panic for struct elements")
+		}
+	}()
+	x.Fingerprint(nil)
+}
+
+func TestSet_PopNDrain(t *testing.T) {
+	s := NewSet(1, 2, 3, 4, 5)
+	popped := s.PopN(2)
+	if len(popped) != 2 || s.Len() != 3 || s.HasAny(popped...) {
+		t.Errorf("expected 2 elements removed, got %v, left %v", popped, s.Elements())
+	}
+	if got := s.PopN(10); len(got) != 3 || !s.IsEmpty() {
+		t.Errorf("expected remaining 3 elements, got %v", got)
+	}
+
+	pending := NewSet("a", "b", "c")
+	for range pending.Drain() {
+		break // the yielded element is gone, the rest stay
+	}
+	if pending.Len() != 2 {
+		t.Errorf("expected 2 left after early stop, got %d", pending.Len())
+	}
+	drained := slices.Collect(pending.Drain())
+	if len(drained) != 2 || !pending.IsEmpty() {
+		t.Errorf("expected full drain, got %v", drained)
+	}
+}
+
+func TestSet_PowerSetCombinations(t *testing.T) {
+	s := NewSet("a", "b", "c", "d")
+	subsets := NewSet[string]()
+	for subset := range s.PowerSet() {
+		slices.Sort(subset)
+		subsets.Add(strings.Join(subset, ""))
+	}
+	if subsets.Len() != 16 || !subsets.HasAll("", "abcd", "bd") {
+		t.Errorf("expected 16 distinct subsets, got %d", subsets.Len())
+	}
+
+	pairs := NewSet[string]()
+	for combo := range s.Combinations(2) {
+		slices.Sort(combo)
+		pairs.Add(strings.Join(combo, ""))
+	}
+	if pairs.Len() != 6 || !pairs.HasAll("ab", "cd", "ad") {
+		t.Errorf("expected 6 pairs, got %v", pairs.Elements())
+	}
+	count := func(k int) int {
+		n := 0
+		for range s.Combinations(k) {
+			n++
+		}
+		return n
+	}
+	if count(0) != 1 || count(4) != 1 || count(5) != 0 || count(-1) != 0 {
+		t.Error("unexpected combination counts at the edges")
+	}
+	for range s.PowerSet() {
+		break // early exit must not panic
+	}
+}
+
 func BenchmarkSet_Add(b *testing.B) {
 	s := NewSet[int]()
 	for i := 0; i < b.N; i++ {
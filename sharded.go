@@ -1,10 +1,21 @@
 package mappo
 
 import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
 	"hash/maphash"
+	"io"
+	"iter"
 	"math/bits"
-	"reflect"
+	"path"
 	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/puzpuzpuz/xsync/v3"
 )
@@ -18,25 +29,160 @@ type padding [cacheLineSize]byte
 
 // shard holds a portion of the map with its own lock-free structure.
 type shard[K comparable, V any] struct {
-	_    padding
-	data *xsync.MapOf[K, V]
-	_    padding
+	_ padding
+	// data is replaced wholesale by Clear and ClearAsync. Writers load it
+	// under mu, so a write never lands in a map that was already swapped out.
+	data atomic.Pointer[xsync.MapOf[K, shardedEntry[V]]]
+	// mu is shared by writers and held exclusively by RangeConsistent
+	// while it copies the shard, and by swap. Reads never take it. It is reader
+	// biased so concurrent writers claim spread-out slots instead of one shared counter.
+	mu *xsync.RBMutex
+	// ops counts key operations when ShardedConfig.EnableStats is set
+	ops atomic.Int64
+	// ring bounds the shard when ShardedConfig.MaxPerShard is set
+	ring  *shardRing[K]
+	index int
+	_     padding
+}
+
+// m returns the shard's map.
+func (s *shard[K, V]) m() *xsync.MapOf[K, shardedEntry[V]] {
+	return s.data.Load()
+}
+
+func (s *shard[K, V]) store(key K, e shardedEntry[V]) {
+	if s.ring != nil {
+		s.computeBounded(key, func(shardedEntry[V], bool) (shardedEntry[V], bool) {
+			return e, false
+		})
+		return
+	}
+	t := s.mu.RLock()
+	s.m().Store(key, e)
+	s.mu.RUnlock(t)
+}
+
+func (s *shard[K, V]) compute(key K, fn func(shardedEntry[V], bool) (shardedEntry[V], bool)) {
+	if s.ring != nil {
+		s.computeBounded(key, fn)
+		return
+	}
+	t := s.mu.RLock()
+	defer s.mu.RUnlock(t) // fn may panic
+	s.m().Compute(key, fn)
+}
+
+func (s *shard[K, V]) loadAndDelete(key K) (shardedEntry[V], bool) {
+	t := s.mu.RLock()
+	defer s.mu.RUnlock(t)
+	return s.m().LoadAndDelete(key)
+}
+
+func (s *shard[K, V]) delete(key K) {
+	t := s.mu.RLock()
+	s.m().Delete(key)
+	s.mu.RUnlock(t)
+}
+
+// swap publishes data as the shard's map, dropping the old one, and empties
+// the ring of a bounded shard. Writers are held off only for the swap itself.
+func (s *shard[K, V]) swap(data *xsync.MapOf[K, shardedEntry[V]]) {
+	if s.ring != nil {
+		// Same lock order as admit: ring, then shard
+		s.ring.mu.Lock()
+		defer s.ring.mu.Unlock()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Store(data)
+	if s.ring != nil {
+		s.ring.reset()
+	}
+}
+
+// shardedEntry is a value stored inline with its optional expiration.
+type shardedEntry[V any] struct {
+	value      V
+	expiration int64 // UnixNano, 0 means no expiration
 }
 
 // Sharded provides a generic sharded map for high-concurrency scenarios.
 // It reduces lock contention by splitting the map into multiple shards.
+//
+// Values are stored inline, so Compute and Update copy V in and out of fn.
+// For large structs store pointers instead: Compute then copies only the
+// pointer, and fn may edit the pointee in place while writers of the key
+// are serialized. Readers that Get the pointer see such edits as they
+// happen, so return a modified copy from fn when readers need a stable view.
 type Sharded[K comparable, V any] struct {
 	shards []shard[K, V]
 	mask   uint64
 	seed   maphash.Seed
-	hash   func(K, maphash.Seed) uint64
+	hash   Hasher[K]
+	clock  Clock
+	equal  func(a, b V) bool
+
+	countOps bool
+	metrics  ShardedMetrics
+	mapOpts  []func(*xsync.MapConfig)
 }
 
 // ShardedConfig holds configuration for Sharded map.
+// Options that depend on the key or value type live in ShardedOptions.
 type ShardedConfig struct {
 	// ShardCount is the number of shards (rounded up to power of 2).
 	// If <= 0, defaults to NumCPU.
 	ShardCount int
+	// Clock is the time source for TTLs. Optional, nil uses SystemClock.
+	Clock Clock
+	// Seed makes the key to shard mapping deterministic across processes,
+	// e.g. to keep per-shard files aligned after a restart; ShardCount must
+	// stay the same too. Zero uses a random per-process seed. Seed needs a
+	// string, integer or bool key; set ShardedOptions.Hasher to
+	// SeededHasherFunc otherwise.
+	Seed uint64
+	// EnableStats counts operations per shard, reported by Stats.Ops
+	// to spot hot shards. Off by default since it adds an atomic add per operation.
+	EnableStats bool
+	// MaxPerShard bounds the number of entries in each shard, evicting
+	// approximately the least recently used entry of a full shard on insert.
+	// The total bound is MaxPerShard times the shard count. Zero means unbounded.
+	MaxPerShard int
+	// SizeHint is the expected total number of entries. Each shard is
+	// pre-sized for its share so bulk loads avoid repeated table growth.
+	// Zero uses xsync's default initial size.
+	SizeHint int
+	// Metrics receives per-shard operation counters, e.g. to export them to
+	// Prometheus or OpenTelemetry. Nil disables it.
+	Metrics ShardedMetrics
+}
+
+// ShardedOptions extends ShardedConfig with options typed by the key or value.
+type ShardedOptions[K comparable, V any] struct {
+	ShardedConfig
+	// Hasher selects the shard for a key. Defaults to DefaultHasher,
+	// or SeededHasher(Seed) when Seed is set.
+	// Set it for keys with pointer fields or custom identity semantics.
+	Hasher Hasher[K]
+	// Equal compares values in CompareAndSwap and CompareAndDelete.
+	// Defaults to == for comparable types and reflect.DeepEqual otherwise.
+	Equal func(a, b V) bool
+}
+
+// ShardedMetrics receives per-shard counters from a Sharded map.
+// Methods are called inline on every operation, so implementations must be
+// safe for concurrent use and cheap, e.g. pre-resolved counters per shard.
+type ShardedMetrics interface {
+	// Get is called for every lookup, Miss additionally when it found nothing.
+	Get(shard int)
+	Miss(shard int)
+	// Set is called for every write that stores a value.
+	Set(shard int)
+	// Delete is called for every entry removed explicitly, not by expiration or eviction.
+	Delete(shard int)
+	// Expire is called for every expired entry removed, whether found by a
+	// lookup, an iteration, ClearIf or PurgeExpired.
+	Expire(shard int)
 }
 
 // DefaultShardedConfig returns default configuration.
@@ -53,6 +199,12 @@ func NewSharded[K comparable, V any]() *Sharded[K, V] {
 
 // NewShardedWithConfig creates a new sharded map with custom configuration.
 func NewShardedWithConfig[K comparable, V any](cfg ShardedConfig) *Sharded[K, V] {
+	return NewShardedWithOptions(ShardedOptions[K, V]{ShardedConfig: cfg})
+}
+
+// NewShardedWithOptions creates a new sharded map with custom configuration,
+// including the options typed by K and V.
+func NewShardedWithOptions[K comparable, V any](cfg ShardedOptions[K, V]) *Sharded[K, V] {
 	shardCount := cfg.ShardCount
 	if shardCount <= 0 {
 		shardCount = runtime.NumCPU()
@@ -69,15 +221,45 @@ func NewShardedWithConfig[K comparable, V any](cfg ShardedConfig) *Sharded[K, V]
 	}
 	shardCount = n
 
+	hash := cfg.Hasher
+	switch {
+	case hash != nil:
+	case cfg.Seed != 0:
+		hash = SeededHasher[K](cfg.Seed)
+	default:
+		hash = makeHasher[K]()
+	}
+
 	sm := &Sharded[K, V]{
 		shards: make([]shard[K, V], shardCount),
 		mask:   uint64(shardCount - 1),
 		seed:   maphash.MakeSeed(),
-		hash:   makeHasher[K](),
+		hash:   hash,
+		clock:  clockOrSystem(cfg.Clock),
+		equal:  cfg.Equal,
+
+		countOps: cfg.EnableStats,
+		metrics:  cfg.Metrics,
+	}
+	if sm.equal == nil {
+		sm.equal = defaultEqual[V]()
+	}
+
+	if cfg.SizeHint > 0 {
+		perShard := (cfg.SizeHint + shardCount - 1) / shardCount
+		if cfg.MaxPerShard > 0 {
+			perShard = min(perShard, cfg.MaxPerShard)
+		}
+		sm.mapOpts = append(sm.mapOpts, xsync.WithPresize(perShard))
 	}
 
 	for i := range sm.shards {
-		sm.shards[i].data = xsync.NewMapOf[K, V]()
+		sm.shards[i].data.Store(sm.newShardMap())
+		sm.shards[i].index = i
+		sm.shards[i].mu = xsync.NewRBMutex()
+		if cfg.MaxPerShard > 0 {
+			sm.shards[i].ring = newShardRing[K](cfg.MaxPerShard)
+		}
 	}
 
 	return sm
@@ -89,39 +271,119 @@ func (sm *Sharded[K, V]) shardIndex(key K) int {
 }
 
 func (sm *Sharded[K, V]) getShard(key K) *shard[K, V] {
-	return &sm.shards[sm.shardIndex(key)]
+	s := &sm.shards[sm.shardIndex(key)]
+	if sm.countOps {
+		s.ops.Add(1)
+	}
+	return s
 }
 
-// Get retrieves a value. Safe for concurrent use.
+// Get retrieves a value. Returns false if key doesn't exist or is expired.
+// Safe for concurrent use.
 func (sm *Sharded[K, V]) Get(key K) (V, bool) {
-	shard := sm.getShard(key)
-	return shard.data.Load(key)
+	return sm.load(sm.getShard(key), key)
+}
+
+// load reads key from shard, removing it if expired.
+func (sm *Sharded[K, V]) load(shard *shard[K, V], key K) (V, bool) {
+	if sm.metrics != nil {
+		sm.metrics.Get(shard.index)
+	}
+	e, ok := shard.m().Load(key)
+	if !ok {
+		sm.recordMiss(shard)
+		var zero V
+		return zero, false
+	}
+	if sm.expired(e) {
+		sm.removeExpired(shard, key)
+		sm.recordMiss(shard)
+		var zero V
+		return zero, false
+	}
+	shard.touch(key)
+	return e.value, true
 }
 
-// Set sets a value. Safe for concurrent use.
+// Set sets a value with no expiration. Safe for concurrent use.
 func (sm *Sharded[K, V]) Set(key K, val V) {
 	shard := sm.getShard(key)
-	shard.data.Store(key, val)
+	shard.store(key, shardedEntry[V]{value: val})
+	sm.recordSet(shard)
+}
+
+// SetTTL sets a value that expires after ttl. A ttl <= 0 means no expiration.
+// API matches Concurrent.SetTTL
+func (sm *Sharded[K, V]) SetTTL(key K, val V, ttl time.Duration) {
+	shard := sm.getShard(key)
+	shard.store(key, shardedEntry[V]{value: val, expiration: sm.expiration(ttl)})
+	sm.recordSet(shard)
+}
+
+// GetMany retrieves multiple values in one call, visiting each shard once.
+// Missing and expired keys are omitted from the result.
+// API matches Concurrent.GetMany
+func (sm *Sharded[K, V]) GetMany(keys []K) map[K]V {
+	result := make(map[K]V, len(keys))
+	sm.forEachShard(keys, func(shard *shard[K, V], batch []K) {
+		for _, key := range batch {
+			if v, ok := sm.load(shard, key); ok {
+				result[key] = v
+			}
+		}
+	})
+	return result
+}
+
+// SetMany stores multiple values with no expiration, visiting each shard once.
+// API matches Concurrent.SetMany
+func (sm *Sharded[K, V]) SetMany(items map[K]V) {
+	sm.SetManyTTL(items, 0)
+}
+
+// SetManyTTL stores multiple values sharing the same TTL.
+// API matches Concurrent.SetManyTTL
+func (sm *Sharded[K, V]) SetManyTTL(items map[K]V, ttl time.Duration) {
+	keys := make([]K, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	exp := sm.expiration(ttl)
+	sm.forEachShard(keys, func(shard *shard[K, V], batch []K) {
+		for _, key := range batch {
+			shard.store(key, shardedEntry[V]{value: items[key], expiration: exp})
+			sm.recordSet(shard)
+		}
+	})
 }
 
 // SetIfAbsent sets the value only if the key doesn't exist.
 // Returns the actual value and true if loaded (already existed).
 func (sm *Sharded[K, V]) SetIfAbsent(key K, val V) (V, bool) {
+	return sm.SetIfAbsentTTL(key, val, 0)
+}
+
+// SetIfAbsentTTL is like SetIfAbsent but the stored value expires after ttl.
+// Expired entries count as absent.
+func (sm *Sharded[K, V]) SetIfAbsentTTL(key K, val V, ttl time.Duration) (V, bool) {
 	shard := sm.getShard(key)
 
 	var actual V
 	var loaded bool
 
-	shard.data.Compute(key, func(oldV V, exists bool) (V, bool) {
-		if exists {
-			actual = oldV
+	shard.compute(key, func(old shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+		if sm.live(old, exists) {
+			actual = old.value
 			loaded = true
-			return oldV, false // delete=false, keep existing
+			return old, false // delete=false, keep existing
 		}
 		actual = val
 		loaded = false
-		return val, false // delete=false, store new
+		return shardedEntry[V]{value: val, expiration: sm.expiration(ttl)}, false // delete=false, store new
 	})
+	if !loaded {
+		sm.recordSet(shard)
+	}
 
 	return actual, loaded
 }
@@ -135,28 +397,99 @@ func (sm *Sharded[K, V]) Update(key K, fn func(current V, exists bool) V) V {
 	})
 }
 
+// UpdateE is like Update but lets fn abort with an error, e.g. when a
+// business rule fails. The entry is then left untouched and the error returned.
+func (sm *Sharded[K, V]) UpdateE(key K, fn func(current V, exists bool) (V, error)) (V, error) {
+	return sm.ComputeE(key, func(curr V, exists bool) (V, bool, error) {
+		v, err := fn(curr, exists)
+		return v, true, err
+	})
+}
+
+// GetAndUpdateE is like GetAndUpdate but lets fn abort with an error.
+// On error the entry is left untouched; old and existed still describe it.
+func (sm *Sharded[K, V]) GetAndUpdateE(key K, fn func(current V, exists bool) (V, error)) (old V, updated V, existed bool, err error) {
+	updated, err = sm.ComputeE(key, func(curr V, exists bool) (V, bool, error) {
+		old, existed = curr, exists
+		v, err := fn(curr, exists)
+		return v, true, err
+	})
+	return old, updated, existed, err
+}
+
+// GetAndUpdate atomically applies fn and returns both the previous and the new value.
+// existed reports whether the key was present; old is the zero value otherwise.
+// API matches Concurrent.GetAndUpdate
+func (sm *Sharded[K, V]) GetAndUpdate(key K, fn func(current V, exists bool) V) (old V, updated V, existed bool) {
+	shard := sm.getShard(key)
+	shard.compute(key, func(curr shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+		curr, existed = sm.liveEntry(curr, exists)
+		old = curr.value
+		updated = fn(old, existed)
+		return shardedEntry[V]{value: updated, expiration: curr.expiration}, false // delete=false
+	})
+	sm.recordSet(shard)
+	return old, updated, existed
+}
+
 // Compute allows atomic read-modify-write operations on a key within a shard.
 // The function fn receives the current value (or zero value) and existence flag.
 // It returns the new value and a boolean indicating if the key should be kept (true) or deleted (false).
+// The existing entry's expiration is preserved.
 func (sm *Sharded[K, V]) Compute(key K, fn func(current V, exists bool) (newValue V, keep bool)) V {
 	shard := sm.getShard(key)
 
 	var result V
-	shard.data.Compute(key, func(oldV V, exists bool) (V, bool) {
-		newV, keep := fn(oldV, exists)
+	var kept, removed bool
+	shard.compute(key, func(old shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+		old, live := sm.liveEntry(old, exists)
+		newV, keep := fn(old.value, live)
+		kept, removed = keep, !keep && live
 		if keep {
 			result = newV
-			return newV, false // delete=false
+			return shardedEntry[V]{value: newV, expiration: old.expiration}, false // delete=false
 		}
 		// Delete
 		var zero V
 		result = zero
-		return zero, true // delete=true
+		return old, true // delete=true
 	})
+	sm.recordWrite(shard, kept, removed)
 
 	return result
 }
 
+// ComputeE is like Compute but lets fn abort with an error.
+// When fn returns an error the entry is left untouched and the error is returned.
+// API matches Concurrent.ComputeE
+func (sm *Sharded[K, V]) ComputeE(key K, fn func(current V, exists bool) (newValue V, keep bool, err error)) (V, error) {
+	shard := sm.getShard(key)
+
+	var result V
+	var err error
+	var kept, removed bool
+	shard.compute(key, func(old shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+		cur, live := sm.liveEntry(old, exists)
+		newV, keep, fnErr := fn(cur.value, live)
+		if fnErr != nil {
+			err = fnErr
+			return old, !exists // leave untouched
+		}
+		kept, removed = keep, !keep && live
+		if keep {
+			result = newV
+			return shardedEntry[V]{value: newV, expiration: cur.expiration}, false // delete=false
+		}
+		return old, true // delete=true
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	sm.recordWrite(shard, kept, removed)
+	return result, nil
+}
+
 // Replace replaces the value for a key only if it exists.
 // Returns the old value and true if replaced.
 func (sm *Sharded[K, V]) Replace(key K, val V) (V, bool) {
@@ -165,95 +498,255 @@ func (sm *Sharded[K, V]) Replace(key K, val V) (V, bool) {
 	var old V
 	var replaced bool
 
-	shard.data.Compute(key, func(current V, exists bool) (V, bool) {
-		if !exists {
-			var zero V
-			return zero, true // delete=true, no create
+	shard.compute(key, func(current shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+		if !sm.live(current, exists) {
+			return current, true // delete=true, no create
 		}
-		old = current
+		old = current.value
 		replaced = true
-		return val, false // delete=false
+		return shardedEntry[V]{value: val, expiration: current.expiration}, false // delete=false
 	})
+	sm.recordWrite(shard, replaced, false)
 
 	return old, replaced
 }
 
 // CompareAndSwap swaps the value if the current value matches old.
+// Values are compared with ShardedOptions.Equal, see CompareAndDelete for the default.
+// The entry keeps its expiration.
+// API matches Concurrent.CompareAndSwap
 func (sm *Sharded[K, V]) CompareAndSwap(key K, old V, newV V) bool {
+	eq := sm.equal
+	return sm.CompareAndSwapFunc(key, func(current V) bool {
+		return eq(current, old)
+	}, newV)
+}
+
+// CompareAndSwapFunc swaps the value only if match returns true for the current value.
+// The entry keeps its expiration.
+// API matches Concurrent.CompareAndSwapFunc
+func (sm *Sharded[K, V]) CompareAndSwapFunc(key K, match func(current V) bool, newV V) bool {
 	shard := sm.getShard(key)
 	var swapped bool
-	shard.data.Compute(key, func(current V, exists bool) (V, bool) {
-		if !exists {
-			swapped = false
-			var zero V
-			return zero, true // delete=true, no store
-		}
-
-		// Fast path: direct comparison via any() for comparable types
-		// This avoids reflection overhead for primitives, strings, etc.
-		if any(current) == any(old) {
-			swapped = true
-			return newV, false // delete=false, store
+	shard.compute(key, func(current shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+		if !sm.live(current, exists) {
+			return current, true // delete=true, no store
 		}
-
-		// Slow path: use reflection for complex types
-		if !reflect.DeepEqual(current, old) {
-			swapped = false
+		if !match(current.value) {
 			return current, false // delete=false, keep
 		}
-
 		swapped = true
-		return newV, false // delete=false, store
+		return shardedEntry[V]{value: newV, expiration: current.expiration}, false // delete=false, store
 	})
+	sm.recordWrite(shard, swapped, false)
 	return swapped
 }
 
+// CompareAndDelete deletes the key only if its current value equals old.
+// Values are compared with ShardedOptions.Equal, which defaults to == for
+// comparable types and reflect.DeepEqual otherwise.
+// API matches Concurrent.CompareAndDelete
+func (sm *Sharded[K, V]) CompareAndDelete(key K, old V) bool {
+	eq := sm.equal
+	return sm.CompareAndDeleteFunc(key, func(current V) bool {
+		return eq(current, old)
+	})
+}
+
+// CompareAndDeleteFunc deletes the key only if match returns true for its current value.
+func (sm *Sharded[K, V]) CompareAndDeleteFunc(key K, match func(current V) bool) bool {
+	shard := sm.getShard(key)
+	var deleted bool
+	shard.compute(key, func(current shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+		if !sm.live(current, exists) {
+			return current, true // drop expired, never create
+		}
+		if !match(current.value) {
+			return current, false // keep existing
+		}
+		deleted = true
+		return current, true // delete=true
+	})
+	sm.recordWrite(shard, false, deleted)
+	return deleted
+}
+
 // Delete removes a key. Safe for concurrent use.
 func (sm *Sharded[K, V]) Delete(key K) bool {
 	shard := sm.getShard(key)
 	existed := false
-	var zero V
-	shard.data.Compute(key, func(current V, found bool) (V, bool) {
-		if found {
-			existed = true
-		}
-		return zero, true // delete=true
+	shard.compute(key, func(current shardedEntry[V], found bool) (shardedEntry[V], bool) {
+		existed = sm.live(current, found)
+		return current, true // delete=true
 	})
+	sm.recordWrite(shard, false, existed)
 	return existed
 }
 
-// Clear resets all shards.
+// LoadAndDelete deletes a key and returns its previous value.
+// Returns false if the key didn't exist or was expired.
+// API matches Concurrent.LoadAndDelete
+func (sm *Sharded[K, V]) LoadAndDelete(key K) (V, bool) {
+	shard := sm.getShard(key)
+	var old V
+	var loaded bool
+	shard.compute(key, func(current shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+		if sm.live(current, exists) {
+			old, loaded = current.value, true
+		}
+		return current, true // delete=true
+	})
+	sm.recordWrite(shard, false, loaded)
+	return old, loaded
+}
+
+// Swap stores a value with no expiration and returns the previous value.
+// Returns false if the key didn't exist or was expired.
+// API matches Concurrent.Swap
+func (sm *Sharded[K, V]) Swap(key K, val V) (old V, loaded bool) {
+	shard := sm.getShard(key)
+	shard.compute(key, func(current shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+		if sm.live(current, exists) {
+			old, loaded = current.value, true
+		}
+		return shardedEntry[V]{value: val}, false // delete=false: store
+	})
+	sm.recordSet(shard)
+	return old, loaded
+}
+
+// DeleteMany removes multiple keys, visiting each shard once,
+// and returns how many existed and were not expired.
+// API matches Concurrent.DeleteMany
+func (sm *Sharded[K, V]) DeleteMany(keys []K) int {
+	n := 0
+	sm.forEachShard(keys, func(shard *shard[K, V], batch []K) {
+		for _, key := range batch {
+			if e, existed := shard.loadAndDelete(key); existed && !sm.expired(e) {
+				sm.recordWrite(shard, false, true)
+				n++
+			}
+		}
+	})
+	return n
+}
+
+// Clear resets all shards. Each shard gets a fresh map, allocated before
+// its lock is taken, so writers wait only for a pointer swap. With SizeHint
+// the fresh maps are presized, and allocating and zeroing them is most of
+// Clear's cost; use ClearAsync to keep that off the caller's path.
 func (sm *Sharded[K, V]) Clear() {
 	for i := range sm.shards {
-		sm.shards[i].data.Clear()
+		sm.shards[i].swap(sm.newShardMap())
 	}
 }
 
+// ClearAsync empties the map in a background goroutine and returns at once.
+// Shard by shard, the goroutine allocates a fresh map, swaps it in and drops
+// the old generation, leaving its entries to the garbage collector.
+// Until the returned channel is closed, reads may still see entries from
+// before the call. Writes racing with a shard's swap land either in the old
+// generation (and are dropped) or in the new one (and are kept).
+// The channel is closed once every shard has been swapped and the old
+// generation released.
+func (sm *Sharded[K, V]) ClearAsync() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range sm.shards {
+			sm.shards[i].swap(sm.newShardMap())
+		}
+	}()
+	return done
+}
+
+// newShardMap allocates an empty map for one shard, honoring SizeHint.
+func (sm *Sharded[K, V]) newShardMap() *xsync.MapOf[K, shardedEntry[V]] {
+	return xsync.NewMapOf[K, shardedEntry[V]](sm.mapOpts...)
+}
+
 // ClearIf removes entries matching predicate and returns count removed.
+// Expired entries are purged along the way but not counted.
 func (sm *Sharded[K, V]) ClearIf(shouldRemove func(K, V) bool) int {
-	var total int64
+	total := 0
 	for i := range sm.shards {
-		shard := &sm.shards[i]
-		var toDelete []K
-		shard.data.Range(func(k K, v V) bool {
-			if shouldRemove(k, v) {
-				toDelete = append(toDelete, k)
+		total += sm.clearShardIf(&sm.shards[i], shouldRemove)
+	}
+	return total
+}
+
+// ClearIfParallel is like ClearIf but scans up to workers shards concurrently,
+// e.g. to purge one tenant from a large map. shouldRemove must be safe for
+// concurrent use. workers <= 1 behaves like ClearIf.
+func (sm *Sharded[K, V]) ClearIfParallel(shouldRemove func(K, V) bool, workers int) int {
+	if workers <= 1 {
+		return sm.ClearIf(shouldRemove)
+	}
+	var total atomic.Int64
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(workers, len(sm.shards)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(sm.shards) {
+					return
+				}
+				total.Add(int64(sm.clearShardIf(&sm.shards[i], shouldRemove)))
 			}
-			return true
+		}()
+	}
+	wg.Wait()
+	return int(total.Load())
+}
+
+// clearShardIf removes the shard's entries matching shouldRemove, and expired
+// ones. Candidates found by the scan are re-checked against their current
+// value when deleted, so an entry rewritten meanwhile to no longer match is
+// kept. Returns the number of matching entries removed.
+func (sm *Sharded[K, V]) clearShardIf(shard *shard[K, V], shouldRemove func(K, V) bool) int {
+	var candidates []K
+	shard.m().Range(func(k K, e shardedEntry[V]) bool {
+		if sm.expired(e) || shouldRemove(k, e.value) {
+			candidates = append(candidates, k)
+		}
+		return true
+	})
+	removed := 0
+	for _, k := range candidates {
+		var matched, expired bool
+		shard.compute(k, func(current shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+			switch {
+			case !exists:
+			case sm.expired(current):
+				expired = true
+			case shouldRemove(k, current.value):
+				matched = true
+			default:
+				return current, false // rewritten since the scan, keep
+			}
+			return current, true
 		})
-		for _, k := range toDelete {
-			shard.data.Delete(k)
-			total++
+		if matched {
+			removed++
+			sm.recordWrite(shard, false, true)
+		} else if expired {
+			sm.recordExpire(shard)
 		}
 	}
-	return int(total)
+	if len(candidates) > 0 {
+		shard.pruneRing()
+	}
+	return removed
 }
 
 // Len returns the total number of items across all shards.
 func (sm *Sharded[K, V]) Len() int {
 	var total int
 	for i := range sm.shards {
-		total += sm.shards[i].data.Size()
+		total += sm.shards[i].m().Size()
 	}
 	return total
 }
@@ -267,26 +760,66 @@ func (sm *Sharded[K, V]) Size() int {
 func (sm *Sharded[K, V]) ShardStats() []int {
 	stats := make([]int, len(sm.shards))
 	for i := range sm.shards {
-		stats[i] = sm.shards[i].data.Size()
+		stats[i] = sm.shards[i].m().Size()
 	}
 	return stats
 }
 
 // Range iterates through all items. Return false to stop iteration.
+// Expired items are skipped and deleted.
 // API matches Concurrent.Range
 func (sm *Sharded[K, V]) Range(fn func(K, V) bool) {
 	for i := range sm.shards {
-		cont := true
-		sm.shards[i].data.Range(func(k K, v V) bool {
-			cont = fn(k, v)
-			return cont
-		})
-		if !cont {
+		if !sm.rangeShard(&sm.shards[i], fn) {
 			return
 		}
 	}
 }
 
+// rangeShard calls fn for the live items of one shard, deleting expired ones.
+// Returns false if fn stopped the iteration.
+func (sm *Sharded[K, V]) rangeShard(shard *shard[K, V], fn func(K, V) bool) bool {
+	cont := true
+	shard.m().Range(func(k K, e shardedEntry[V]) bool {
+		if sm.expired(e) {
+			sm.removeExpired(shard, k)
+			return true
+		}
+		cont = fn(k, e.value)
+		return cont
+	})
+	return cont
+}
+
+// All returns an iterator over all non-expired key-value pairs.
+// Expired entries are skipped and deleted, as in Range.
+// API matches Concurrent.All
+func (sm *Sharded[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		sm.Range(yield)
+	}
+}
+
+// KeysSeq returns an iterator over all non-expired keys.
+// API matches Concurrent.KeysSeq
+func (sm *Sharded[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		sm.Range(func(k K, _ V) bool {
+			return yield(k)
+		})
+	}
+}
+
+// ValuesSeq returns an iterator over all non-expired values.
+// API matches Concurrent.ValuesSeq
+func (sm *Sharded[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		sm.Range(func(_ K, v V) bool {
+			return yield(v)
+		})
+	}
+}
+
 // Keys returns all keys in the map.
 func (sm *Sharded[K, V]) Keys() []K {
 	keys := make([]K, 0, sm.Len())
@@ -297,6 +830,34 @@ func (sm *Sharded[K, V]) Keys() []K {
 	return keys
 }
 
+// KeysSorted returns all keys ordered by less.
+func (sm *Sharded[K, V]) KeysSorted(less func(a, b K) bool) []K {
+	keys := sm.Keys()
+	sort.Slice(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+	return keys
+}
+
+// ExportOrdered copies all items into a new Ordered map, in key order by less.
+// Each shard is captured at a single point in time, see RangeConsistent.
+// The result is not protected for concurrent use.
+func (sm *Sharded[K, V]) ExportOrdered(less func(a, b K) bool) *Ordered[K, V] {
+	snap := sm.Snapshot()
+	keys := make([]K, 0, len(snap))
+	for k := range snap {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+	o := NewOrdered[K, V]()
+	for _, k := range keys {
+		o.Set(k, snap[k])
+	}
+	return o
+}
+
 // Values returns all values in the map.
 func (sm *Sharded[K, V]) Values() []V {
 	values := make([]V, 0, sm.Len())
@@ -307,7 +868,111 @@ func (sm *Sharded[K, V]) Values() []V {
 	return values
 }
 
-// Has returns true if the key exists.
+// RangeConsistent iterates through all items, seeing each shard at a single
+// point in time: writes to a shard wait while it is copied, then fn runs on
+// the copy without holding any lock, so fn may write to the map.
+// Shards are captured one after another, not all at once.
+// Expired items are skipped. Return false to stop iteration.
+func (sm *Sharded[K, V]) RangeConsistent(fn func(K, V) bool) {
+	type item struct {
+		key   K
+		value V
+	}
+	var items []item
+	for i := range sm.shards {
+		shard := &sm.shards[i]
+		items = items[:0]
+		shard.mu.Lock()
+		shard.m().Range(func(k K, e shardedEntry[V]) bool {
+			if !sm.expired(e) {
+				items = append(items, item{k, e.value})
+			}
+			return true
+		})
+		shard.mu.Unlock()
+		for _, it := range items {
+			if !fn(it.key, it.value) {
+				return
+			}
+		}
+	}
+}
+
+// Snapshot returns a copy of all live items, each shard captured at a single
+// point in time. See RangeConsistent.
+func (sm *Sharded[K, V]) Snapshot() map[K]V {
+	out := make(map[K]V, sm.Len())
+	sm.RangeConsistent(func(k K, v V) bool {
+		out[k] = v
+		return true
+	})
+	return out
+}
+
+// RangeCtx is like Range but checks ctx between entries and stops once it is done.
+// Returns ctx.Err() if the scan was cancelled.
+// API matches Concurrent.RangeCtx
+func (sm *Sharded[K, V]) RangeCtx(ctx context.Context, fn func(K, V) bool) error {
+	return rangeCtx(ctx, sm.Range, fn)
+}
+
+// TransformValues atomically rewrites every entry with fn and returns how many were rewritten.
+// With parallelism > 1 keys are processed by that many goroutines, so fn must be safe
+// for concurrent use. Keys added during the call may or may not be visited.
+// API matches Concurrent.TransformValues
+func (sm *Sharded[K, V]) TransformValues(fn func(K, V) V, parallelism int) int {
+	return transformKeys(sm.Keys(), parallelism, func(key K) bool {
+		var rewritten bool
+		shard := sm.getShard(key)
+		shard.compute(key, func(current shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+			if !sm.live(current, exists) {
+				return current, true // deleted or expired meanwhile, don't create
+			}
+			rewritten = true
+			return shardedEntry[V]{value: fn(key, current.value), expiration: current.expiration}, false // delete=false
+		})
+		sm.recordWrite(shard, rewritten, false)
+		return rewritten
+	})
+}
+
+// KeysWithPrefix returns the keys starting with prefix.
+// Returns nil unless K is a string type.
+// API matches Concurrent.KeysWithPrefix
+func (sm *Sharded[K, V]) KeysWithPrefix(prefix string) []K {
+	return keysWithPrefix(sm.Range, prefix)
+}
+
+// ScanPrefix calls fn for each pair whose key starts with prefix, stopping
+// when fn returns false. Does nothing unless K is a string type.
+func (sm *Sharded[K, V]) ScanPrefix(prefix string, fn func(K, V) bool) {
+	scanKeys(sm.Range, func(k string) bool {
+		return strings.HasPrefix(k, prefix)
+	}, fn)
+}
+
+// ScanMatch calls fn for each pair whose key matches the shell glob, stopping
+// when fn returns false. The syntax is that of path.Match, so "*" does not
+// cross "/". Does nothing unless K is a string type.
+// Returns path.ErrBadPattern if glob is malformed.
+func (sm *Sharded[K, V]) ScanMatch(glob string, fn func(K, V) bool) error {
+	if _, err := path.Match(glob, ""); err != nil {
+		return err
+	}
+	scanKeys(sm.Range, func(k string) bool {
+		ok, _ := path.Match(glob, k)
+		return ok
+	}, fn)
+	return nil
+}
+
+// FindAll returns all pairs for which pred returns true.
+// API matches Concurrent.FindAll
+func (sm *Sharded[K, V]) FindAll(pred func(K, V) bool) map[K]V {
+	return findAll(sm.Range, pred)
+}
+
+// Has returns true if the key exists and is not expired.
 func (sm *Sharded[K, V]) Has(key K) bool {
 	_, ok := sm.Get(key)
 	return ok
@@ -317,3 +982,210 @@ func (sm *Sharded[K, V]) Has(key K) bool {
 func (sm *Sharded[K, V]) GetOrSet(key K, val V) (actual V, loaded bool) {
 	return sm.SetIfAbsent(key, val)
 }
+
+// PurgeExpired removes all expired entries and returns how many were removed.
+// API matches Concurrent.PurgeExpired
+func (sm *Sharded[K, V]) PurgeExpired() int {
+	removed := 0
+	for i := range sm.shards {
+		shard := &sm.shards[i]
+		shard.m().Range(func(k K, e shardedEntry[V]) bool {
+			if sm.expired(e) && sm.removeExpired(shard, k) {
+				removed++
+			}
+			return true
+		})
+	}
+	return removed
+}
+
+// MarshalJSON implements json.Marshaler, merging all shards into one list.
+// Only non-expired entries are written, each with its remaining TTL.
+// The format is shared with Concurrent.
+func (sm *Sharded[K, V]) MarshalJSON() ([]byte, error) {
+	now := sm.clock.Now().UnixNano()
+	entries := make([]concurrentJSONEntry[K, V], 0, sm.Len())
+	sm.rangeEntries(func(key K, entry shardedEntry[V]) {
+		e := concurrentJSONEntry[K, V]{Key: key, Value: entry.value}
+		if entry.expiration > 0 {
+			e.TTL = time.Duration(entry.expiration - now)
+		}
+		entries = append(entries, e)
+	})
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// Entries are merged into the map, re-sharded by its own hasher, with their
+// remaining TTL counted from now.
+// A zero Sharded is initialized with default configuration.
+func (sm *Sharded[K, V]) UnmarshalJSON(b []byte) error {
+	var entries []concurrentJSONEntry[K, V]
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+	sm.initZero()
+	for _, e := range entries {
+		sm.SetTTL(e.Key, e.Value, e.TTL)
+	}
+	return nil
+}
+
+// SaveTo writes all non-expired entries with their absolute expirations to w using gob.
+// Interface-typed values must have their concrete types registered with gob.Register.
+// API matches Concurrent.SaveTo
+func (sm *Sharded[K, V]) SaveTo(w io.Writer) error {
+	entries := make([]concurrentGobEntry[K, V], 0, sm.Len())
+	sm.rangeEntries(func(key K, entry shardedEntry[V]) {
+		entries = append(entries, concurrentGobEntry[K, V]{Key: key, Value: entry.value, Expiration: entry.expiration})
+	})
+	return gob.NewEncoder(w).Encode(entries)
+}
+
+// LoadFrom merges entries written by SaveTo into the map, re-sharding them.
+// Expirations are absolute, so entries that expired in the meantime are skipped.
+// A zero Sharded is initialized with default configuration.
+// API matches Concurrent.LoadFrom
+func (sm *Sharded[K, V]) LoadFrom(r io.Reader) error {
+	var entries []concurrentGobEntry[K, V]
+	if err := gob.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+	sm.initZero()
+	for _, e := range entries {
+		entry := shardedEntry[V]{value: e.Value, expiration: e.Expiration}
+		if !sm.expired(entry) {
+			shard := sm.getShard(e.Key)
+			shard.store(e.Key, entry)
+			sm.recordSet(shard)
+		}
+	}
+	return nil
+}
+
+// initZero makes a zero Sharded usable, as if created by NewSharded.
+func (sm *Sharded[K, V]) initZero() {
+	if sm.shards == nil {
+		*sm = *NewSharded[K, V]()
+	}
+}
+
+// rangeEntries calls fn for every non-expired entry across all shards.
+func (sm *Sharded[K, V]) rangeEntries(fn func(K, shardedEntry[V])) {
+	for i := range sm.shards {
+		sm.shards[i].m().Range(func(k K, e shardedEntry[V]) bool {
+			if !sm.expired(e) {
+				fn(k, e)
+			}
+			return true
+		})
+	}
+}
+
+// recordSet reports a stored value to the metrics hook, if any.
+func (sm *Sharded[K, V]) recordSet(shard *shard[K, V]) {
+	if sm.metrics != nil {
+		sm.metrics.Set(shard.index)
+	}
+}
+
+// recordWrite reports a conditional write: stored, removed, or neither.
+func (sm *Sharded[K, V]) recordWrite(shard *shard[K, V], stored, removed bool) {
+	switch {
+	case sm.metrics == nil:
+	case stored:
+		sm.metrics.Set(shard.index)
+	case removed:
+		sm.metrics.Delete(shard.index)
+	}
+}
+
+// recordExpire reports the removal of an expired entry to the metrics hook, if any.
+func (sm *Sharded[K, V]) recordExpire(shard *shard[K, V]) {
+	if sm.metrics != nil {
+		sm.metrics.Expire(shard.index)
+	}
+}
+
+// recordMiss reports a lookup that found nothing to the metrics hook, if any.
+func (sm *Sharded[K, V]) recordMiss(shard *shard[K, V]) {
+	if sm.metrics != nil {
+		sm.metrics.Miss(shard.index)
+	}
+}
+
+// forEachShard groups keys by shard and calls fn once per non-empty shard,
+// hashing each key once. Keys keep their relative order within a batch.
+func (sm *Sharded[K, V]) forEachShard(keys []K, fn func(shard *shard[K, V], batch []K)) {
+	if len(keys) == 0 {
+		return
+	}
+	// Counting sort by shard index
+	idx := make([]int, len(keys))
+	offsets := make([]int, len(sm.shards)+1)
+	for i, key := range keys {
+		idx[i] = sm.shardIndex(key)
+		offsets[idx[i]+1]++
+	}
+	for i := 1; i < len(offsets); i++ {
+		offsets[i] += offsets[i-1]
+	}
+	grouped := make([]K, len(keys))
+	next := slices.Clone(offsets[:len(sm.shards)])
+	for i, key := range keys {
+		grouped[next[idx[i]]] = key
+		next[idx[i]]++
+	}
+	for i := range sm.shards {
+		if lo, hi := offsets[i], offsets[i+1]; lo < hi {
+			if sm.countOps {
+				sm.shards[i].ops.Add(int64(hi - lo))
+			}
+			fn(&sm.shards[i], grouped[lo:hi])
+		}
+	}
+}
+
+// expiration converts a ttl into an absolute UnixNano deadline, 0 if ttl <= 0.
+func (sm *Sharded[K, V]) expiration(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return sm.clock.Now().Add(ttl).UnixNano()
+}
+
+// expired reports whether the entry's TTL has passed.
+// The clock is only read for entries that carry a TTL.
+func (sm *Sharded[K, V]) expired(e shardedEntry[V]) bool {
+	return e.expiration > 0 && sm.clock.Now().UnixNano() > e.expiration
+}
+
+// live reports whether a Compute callback sees a present, non-expired entry.
+func (sm *Sharded[K, V]) live(e shardedEntry[V], exists bool) bool {
+	return exists && !sm.expired(e)
+}
+
+// liveEntry returns e and true if it is live, or a zero entry and false otherwise.
+func (sm *Sharded[K, V]) liveEntry(e shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+	if !sm.live(e, exists) {
+		return shardedEntry[V]{}, false
+	}
+	return e, true
+}
+
+// removeExpired deletes key only if its entry is still expired,
+// so a value stored concurrently is never lost.
+func (sm *Sharded[K, V]) removeExpired(shard *shard[K, V], key K) bool {
+	removed := false
+	shard.compute(key, func(current shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+		if !exists || !sm.expired(current) {
+			return current, !exists // leave untouched
+		}
+		removed = true
+		return current, true
+	})
+	if removed {
+		sm.recordExpire(shard)
+	}
+	return removed
+}
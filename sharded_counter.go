@@ -0,0 +1,91 @@
+package mappo
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+)
+
+// ShardedCounter counts per key for hot keys under heavy contention, e.g.
+// rate limiting a single IP. Each key's count is striped across cache-line
+// padded cells picked at random per Add, so concurrent increments of one key
+// rarely touch the same memory. Get sums the cells, so it costs more than Add
+// and is not a snapshot consistent with concurrent Adds.
+type ShardedCounter[K comparable] struct {
+	m     *Sharded[K, *counterCells]
+	cells int
+}
+
+// counterCells holds the stripes of one key; len is a power of 2.
+type counterCells []counterCell
+
+type counterCell struct {
+	n atomic.Int64
+	_ [cacheLineSize - 8]byte
+}
+
+// NewShardedCounter creates a counter with one stripe per CPU per key.
+func NewShardedCounter[K comparable]() *ShardedCounter[K] {
+	n := 1
+	for n < runtime.GOMAXPROCS(0) {
+		n <<= 1
+	}
+	return &ShardedCounter[K]{
+		m:     NewSharded[K, *counterCells](),
+		cells: n,
+	}
+}
+
+// Add adds delta to the count of key. Missing keys start at zero.
+func (c *ShardedCounter[K]) Add(key K, delta int64) {
+	cells, ok := c.m.Get(key)
+	if !ok {
+		fresh := make(counterCells, c.cells)
+		cells, _ = c.m.SetIfAbsent(key, &fresh)
+	}
+	(*cells)[rand.Uint32()&uint32(len(*cells)-1)].n.Add(delta)
+}
+
+// Inc adds one to the count of key.
+func (c *ShardedCounter[K]) Inc(key K) {
+	c.Add(key, 1)
+}
+
+// Get returns the count of key, zero if missing.
+func (c *ShardedCounter[K]) Get(key K) int64 {
+	cells, ok := c.m.Get(key)
+	if !ok {
+		return 0
+	}
+	return cells.sum()
+}
+
+// Delete removes key and returns its last count.
+// Adds racing with Delete may be lost.
+func (c *ShardedCounter[K]) Delete(key K) int64 {
+	cells, ok := c.m.LoadAndDelete(key)
+	if !ok {
+		return 0
+	}
+	return cells.sum()
+}
+
+// Range calls fn with every key and its count. Return false to stop iteration.
+func (c *ShardedCounter[K]) Range(fn func(key K, count int64) bool) {
+	c.m.Range(func(k K, cells *counterCells) bool {
+		return fn(k, cells.sum())
+	})
+}
+
+// Len returns the number of keys.
+func (c *ShardedCounter[K]) Len() int {
+	return c.m.Len()
+}
+
+func (cells *counterCells) sum() int64 {
+	var total int64
+	for i := range *cells {
+		total += (*cells)[i].n.Load()
+	}
+	return total
+}
@@ -0,0 +1,59 @@
+package mappo
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestShardedCounter(t *testing.T) {
+	c := NewShardedCounter[string]()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				c.Inc("1.2.3.4")
+				c.Add("5.6.7.8", 2)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := c.Get("1.2.3.4"); n != 8000 {
+		t.Errorf("expected 8000, got %d", n)
+	}
+	if n := c.Get("missing"); n != 0 {
+		t.Errorf("expected 0, got %d", n)
+	}
+	total := int64(0)
+	c.Range(func(_ string, n int64) bool {
+		total += n
+		return true
+	})
+	if total != 24000 || c.Len() != 2 {
+		t.Errorf("expected 24000 across 2 keys, got %d across %d", total, c.Len())
+	}
+	if n := c.Delete("5.6.7.8"); n != 16000 || c.Len() != 1 {
+		t.Errorf("expected 16000 deleted, got %d", n)
+	}
+}
+
+func BenchmarkShardedCounter_HotKey(b *testing.B) {
+	b.Run("Increment", func(b *testing.B) {
+		m := NewSharded[string, int64]()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				Increment(m, "hot", int64(1))
+			}
+		})
+	})
+	b.Run("ShardedCounter", func(b *testing.B) {
+		c := NewShardedCounter[string]()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				c.Inc("hot")
+			}
+		})
+	})
+}
@@ -0,0 +1,34 @@
+package mappo
+
+// ShardedLoader is a read-through cache over a Sharded map: Get loads missing
+// keys with the loader and stores the result. Other methods are those of the
+// embedded Sharded and never call the loader.
+type ShardedLoader[K comparable, V any] struct {
+	*Sharded[K, V]
+	load    func(K) (V, error)
+	flights []flightGroup[K, V] // in-flight loads, one group per shard
+}
+
+// WithLoader wraps s so that Get misses call load. Only one load runs per key
+// at a time; concurrent callers wait for its result. Loaded values are
+// stored without expiration; errors are returned to every waiting caller
+// and nothing is stored.
+func WithLoader[K comparable, V any](s *Sharded[K, V], load func(K) (V, error)) *ShardedLoader[K, V] {
+	return &ShardedLoader[K, V]{
+		Sharded: s,
+		load:    load,
+		flights: make([]flightGroup[K, V], s.NumShards()),
+	}
+}
+
+// Get returns the value for key, loading it if missing or expired.
+// If the loader panics, the panic propagates to its caller and waiters retry.
+func (l *ShardedLoader[K, V]) Get(key K) (V, error) {
+	return l.flights[l.ShardIndex(key)].do(key, l.Sharded.Get, func() (V, error) {
+		v, err := l.load(key)
+		if err == nil {
+			l.Set(key, v)
+		}
+		return v, err
+	})
+}
@@ -0,0 +1,155 @@
+package mappo
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/puzpuzpuz/xsync/v3"
+)
+
+// shardRing bounds a shard with CLOCK (second-chance) eviction, an
+// approximation of LRU that keeps reads lock-free: a read only sets the
+// key's ref bit, and the ring is locked only when a new key is admitted.
+// Ref bits live here rather than in the entries, so unbounded shards pay
+// nothing for them.
+type shardRing[K comparable] struct {
+	mu    sync.Mutex
+	slots []ringSlot[K]
+	hand  int
+	max   int
+	// refs maps each admitted key to its ref bit. It is written inside the
+	// shard's Compute for the key, so it changes in step with the entry.
+	refs *xsync.MapOf[K, *atomic.Bool]
+}
+
+// newShardRing creates a ring admitting up to max keys.
+func newShardRing[K comparable](max int) *shardRing[K] {
+	return &shardRing[K]{max: max, refs: xsync.NewMapOf[K, *atomic.Bool]()}
+}
+
+// reset forgets every admitted key. Callers must hold r.mu and keep
+// writers out of the shard, so no ref bit is stored meanwhile.
+func (r *shardRing[K]) reset() {
+	clear(r.slots)
+	r.slots = r.slots[:0]
+	r.hand = 0
+	r.refs.Clear()
+}
+
+// ringSlot tracks one admitted key. The slot is stale once the map no longer
+// holds the key with the same ref, i.e. the key was deleted or re-created.
+type ringSlot[K comparable] struct {
+	key K
+	ref *atomic.Bool
+}
+
+// touch marks key as recently used if the shard is bounded.
+func (s *shard[K, V]) touch(key K) {
+	if s.ring == nil {
+		return
+	}
+	if ref, ok := s.ring.refs.Load(key); ok && !ref.Load() {
+		ref.Store(true)
+	}
+}
+
+// computeBounded is compute for shards with a ring: updated entries keep
+// their ref and are marked used, created entries are admitted to the ring.
+func (s *shard[K, V]) computeBounded(key K, fn func(shardedEntry[V], bool) (shardedEntry[V], bool)) {
+	var added *atomic.Bool
+	func() {
+		t := s.mu.RLock()
+		defer s.mu.RUnlock(t) // fn may panic
+		s.m().Compute(key, func(old shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+			e, del := fn(old, exists)
+			if del {
+				return e, true
+			}
+			if exists {
+				s.touch(key)
+			} else {
+				added = new(atomic.Bool)
+				s.ring.refs.Store(key, added)
+			}
+			return e, false
+		})
+	}()
+	if added != nil {
+		s.admit(key, added)
+	}
+}
+
+// admit gives key a slot in the ring, evicting an entry not used since the
+// hand last passed it once the ring is full.
+func (s *shard[K, V]) admit(key K, ref *atomic.Bool) {
+	r := s.ring
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.slots) < r.max {
+		r.slots = append(r.slots, ringSlot[K]{key: key, ref: ref})
+		return
+	}
+	// Two sweeps clear every ref bit; the bound guards against readers
+	// re-setting them faster than the hand moves.
+	for range 2 * len(r.slots) {
+		slot := r.slots[r.hand]
+		if !s.admitted(slot) {
+			break // stale, reuse
+		}
+		if !slot.ref.Load() {
+			break
+		}
+		slot.ref.Store(false)
+		r.hand = (r.hand + 1) % len(r.slots)
+	}
+	s.evict(r.slots[r.hand])
+	r.slots[r.hand] = ringSlot[K]{key: key, ref: ref}
+	r.hand = (r.hand + 1) % len(r.slots)
+}
+
+// admitted reports whether the shard still holds the key slot was admitted for.
+func (s *shard[K, V]) admitted(slot ringSlot[K]) bool {
+	if _, ok := s.m().Load(slot.key); !ok {
+		return false
+	}
+	ref, ok := s.ring.refs.Load(slot.key)
+	return ok && ref == slot.ref
+}
+
+// evict deletes the slot's key and its ref bit unless the key was re-created
+// since it was admitted. For a key already deleted it only drops the ref bit.
+func (s *shard[K, V]) evict(slot ringSlot[K]) {
+	t := s.mu.RLock()
+	defer s.mu.RUnlock(t)
+	s.m().Compute(slot.key, func(current shardedEntry[V], exists bool) (shardedEntry[V], bool) {
+		if ref, _ := s.ring.refs.Load(slot.key); ref != slot.ref {
+			return current, !exists // leave untouched
+		}
+		s.ring.refs.Delete(slot.key)
+		return current, true
+	})
+}
+
+// pruneRing drops the slots of keys no longer in a bounded shard, e.g. after
+// ClearIf, so the next inserts fill free slots instead of sweeping stale ones.
+func (s *shard[K, V]) pruneRing() {
+	r := s.ring
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	live := r.slots[:0]
+	for _, slot := range r.slots {
+		if s.admitted(slot) {
+			live = append(live, slot)
+		} else {
+			s.evict(slot) // drops the ref bit left by a delete
+		}
+	}
+	clear(r.slots[len(live):])
+	r.slots = live
+	if r.hand >= len(live) {
+		r.hand = 0
+	}
+}
@@ -0,0 +1,68 @@
+package mappo
+
+import "math"
+
+// ShardedStats describes how entries and operations spread across shards.
+type ShardedStats struct {
+	Sizes     []int // Entries per shard, including not yet purged expired ones
+	Size      int
+	Min       int
+	Max       int
+	Mean      float64
+	StdDev    float64
+	Imbalance float64 // Max / Mean; 1 is perfectly even, 0 when empty
+	// Ops counts key operations per shard since creation.
+	// Nil unless ShardedConfig.EnableStats is set.
+	Ops []int64
+}
+
+// HotShards returns the indexes of shards that received more than factor
+// times the mean number of operations, e.g. 2 for twice the average.
+// Returns nil when operation counting is disabled.
+func (s ShardedStats) HotShards(factor float64) []int {
+	if len(s.Ops) == 0 {
+		return nil
+	}
+	var total int64
+	for _, n := range s.Ops {
+		total += n
+	}
+	mean := float64(total) / float64(len(s.Ops))
+	var hot []int
+	for i, n := range s.Ops {
+		if mean > 0 && float64(n) > factor*mean {
+			hot = append(hot, i)
+		}
+	}
+	return hot
+}
+
+// Stats returns per-shard sizes with summary statistics and, when
+// ShardedConfig.EnableStats is set, per-shard operation counts.
+func (sm *Sharded[K, V]) Stats() ShardedStats {
+	s := ShardedStats{Sizes: sm.ShardStats()}
+	s.Min = math.MaxInt
+	for _, n := range s.Sizes {
+		s.Size += n
+		s.Min = min(s.Min, n)
+		s.Max = max(s.Max, n)
+	}
+	s.Mean = float64(s.Size) / float64(len(s.Sizes))
+	var variance float64
+	for _, n := range s.Sizes {
+		d := float64(n) - s.Mean
+		variance += d * d
+	}
+	s.StdDev = math.Sqrt(variance / float64(len(s.Sizes)))
+	if s.Mean > 0 {
+		s.Imbalance = float64(s.Max) / s.Mean
+	}
+
+	if sm.countOps {
+		s.Ops = make([]int64, len(sm.shards))
+		for i := range sm.shards {
+			s.Ops[i] = sm.shards[i].ops.Load()
+		}
+	}
+	return s
+}
@@ -1,9 +1,20 @@
 package mappo
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/maphash"
+	"path"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unsafe"
 )
 
 func TestSharded_Basic(t *testing.T) {
@@ -49,6 +60,93 @@ func TestSharded_Compute(t *testing.T) {
 	}
 }
 
+func TestSharded_ClearAsync(t *testing.T) {
+	m := NewShardedWithConfig[int, int](ShardedConfig{ShardCount: 4, SizeHint: 1000})
+	for i := range 1000 {
+		m.Set(i, i)
+	}
+	// A key in the first shard swapped and one in the last
+	first, last := -1, -1
+	for i := 1000; first < 0 || last < 0; i++ {
+		switch m.ShardIndex(i) {
+		case 0:
+			first = i
+		case m.NumShards() - 1:
+			last = i
+		}
+	}
+
+	// A writer stuck in the last shard holds up its swap, not the caller
+	stuck := &m.shards[m.NumShards()-1]
+	tok := stuck.mu.RLock()
+	done := m.ClearAsync()
+	deadline := time.Now().Add(5 * time.Second)
+	for m.shards[0].m().Size() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the first shard swapped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("expected done to wait for the last shard")
+	default:
+	}
+
+	// Writes after a shard's swap land in the new generation
+	m.Set(first, 1)
+	stuck.mu.RUnlock(tok)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected old shards released")
+	}
+	m.Set(last, 2)
+
+	for i := range 1000 {
+		if m.Has(i) {
+			t.Fatalf("expected %d cleared", i)
+		}
+	}
+	if v, ok := m.Get(first); !ok || v != 1 {
+		t.Errorf("expected write racing ClearAsync kept, got %d/%v", v, ok)
+	}
+	if v, ok := m.Get(last); !ok || v != 2 || m.Len() != 2 {
+		t.Errorf("expected write after ClearAsync kept, got %d/%v, len %d", v, ok, m.Len())
+	}
+}
+
+func TestSharded_ClearAsyncConcurrentWrites(t *testing.T) {
+	m := NewShardedWithConfig[int, int](ShardedConfig{SizeHint: 1000})
+	for i := range 1000 {
+		m.Set(i, i)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				m.Set(1000+i%100, i)
+				m.Get(i % 1000)
+			}
+		}
+	}()
+	<-m.ClearAsync()
+	close(stop)
+	wg.Wait()
+	for i := range 1000 {
+		if m.Has(i) {
+			t.Fatalf("expected %d cleared", i)
+		}
+	}
+}
+
 func TestSharded_ClearIf(t *testing.T) {
 	s := NewSharded[string, int]()
 	s.Set("key1", 1)
@@ -60,6 +158,40 @@ func TestSharded_ClearIf(t *testing.T) {
 	if s.Len() != 1 {
 		t.Error("expected len 1")
 	}
+
+	// A value rewritten between the scan and the delete is re-checked
+	s.Set("key3", 1)
+	rewritten := false
+	removed = s.ClearIf(func(k string, v int) bool {
+		if k == "key3" && !rewritten {
+			rewritten = true
+			s.Set("key3", 3) // runs during the scan, outside any lock
+		}
+		return v == 1
+	})
+	if v, ok := s.Get("key3"); removed != 0 || !ok || v != 3 {
+		t.Errorf("expected rewritten key3=3 kept, got %d/%v after %d removed", v, ok, removed)
+	}
+}
+
+func TestSharded_ClearIfParallel(t *testing.T) {
+	m := NewShardedWithConfig[string, int](ShardedConfig{ShardCount: 16})
+	for i := range 1000 {
+		m.Set(fmt.Sprintf("tenant:%d:%d", i%4, i), i)
+	}
+	purge := func(k string, _ int) bool { return strings.HasPrefix(k, "tenant:1:") }
+
+	for _, workers := range []int{8, 1} {
+		if n := m.ClearIfParallel(purge, workers); workers == 8 && n != 250 {
+			t.Errorf("expected 250 removed, got %d", n)
+		}
+	}
+	if m.Len() != 750 {
+		t.Errorf("expected 750 left, got %d", m.Len())
+	}
+	if len(m.KeysWithPrefix("tenant:1:")) != 0 {
+		t.Error("expected tenant 1 purged")
+	}
 }
 
 func TestSharded_KeysValues(t *testing.T) {
@@ -135,6 +267,973 @@ func TestSharded_Concurrent(t *testing.T) {
 	}
 }
 
+func TestSharded_CompareAndDelete(t *testing.T) {
+	s := NewSharded[string, int]()
+	s.Set("key", 1)
+	if s.CompareAndDelete("key", 2) {
+		t.Error("expected no delete on mismatch")
+	}
+	if !s.CompareAndDelete("key", 1) {
+		t.Error("expected delete on match")
+	}
+	if s.Has("key") {
+		t.Error("expected key gone")
+	}
+	if s.CompareAndDelete("missing", 0) {
+		t.Error("expected no delete for missing key")
+	}
+	if s.Len() != 0 {
+		t.Error("expected missing key not created")
+	}
+
+	m := NewSharded[string, map[string]int]()
+	m.Set("key", map[string]int{"a": 1})
+	if !m.CompareAndDeleteFunc("key", func(v map[string]int) bool { return v["a"] == 1 }) {
+		t.Error("expected delete via predicate")
+	}
+}
+
+func TestSharded_Scans(t *testing.T) {
+	s := NewSharded[string, int]()
+	s.Set("user:1", 1)
+	s.Set("user:2", 2)
+	s.Set("order:1", 3)
+
+	keys := s.KeysWithPrefix("user:")
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"user:1", "user:2"}) {
+		t.Errorf("unexpected keys %v", keys)
+	}
+
+	found := s.FindAll(func(_ string, v int) bool { return v >= 2 })
+	if len(found) != 2 || found["order:1"] != 3 {
+		t.Errorf("unexpected matches %v", found)
+	}
+
+	if keys := NewSharded[int, int]().KeysWithPrefix(""); keys != nil {
+		t.Error("expected nil for non-string keys")
+	}
+}
+
+func TestSharded_ScanPrefixMatch(t *testing.T) {
+	m := NewSharded[string, int]()
+	for _, k := range []string{"tenant:42:a", "tenant:42:b", "tenant:420:a", "tenant:7:a"} {
+		m.Set(k, 1)
+	}
+
+	var got []string
+	m.ScanPrefix("tenant:42:", func(k string, _ int) bool {
+		got = append(got, k)
+		return true
+	})
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"tenant:42:a", "tenant:42:b"}) {
+		t.Errorf("unexpected prefix matches %v", got)
+	}
+
+	got = got[:0]
+	if err := m.ScanMatch("tenant:4*:a", func(k string, _ int) bool {
+		got = append(got, k)
+		return true
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"tenant:420:a", "tenant:42:a"}) {
+		t.Errorf("unexpected glob matches %v", got)
+	}
+
+	calls := 0
+	m.ScanPrefix("tenant:", func(string, int) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("expected scan stopped after 1 call, got %d", calls)
+	}
+	if err := m.ScanMatch("[", func(string, int) bool { return true }); !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("expected ErrBadPattern, got %v", err)
+	}
+
+	ints := NewSharded[int, int]()
+	ints.Set(1, 1)
+	ints.ScanPrefix("", func(int, int) bool {
+		t.Error("expected non-string keys never to match")
+		return true
+	})
+}
+
+func BenchmarkSharded_UpdateLarge(b *testing.B) {
+	type large struct{ n [512]int }
+	m := NewSharded[int, large]()
+	b.Run("Update", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m.Update(1, func(v large, _ bool) large {
+				v.n[0]++
+				return v
+			})
+		}
+	})
+	ptrs := NewSharded[int, *large]()
+	b.Run("Pointer", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ptrs.Compute(1, func(v *large, exists bool) (*large, bool) {
+				if !exists {
+					v = new(large)
+				}
+				v.n[0]++
+				return v, true
+			})
+		}
+	})
+}
+
+func TestSharded_WithLoader(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	errBoom := errors.New("boom")
+	l := WithLoader(NewSharded[string, int](), func(key string) (int, error) {
+		calls.Add(1)
+		switch key {
+		case "fail":
+			return 0, errBoom
+		case "panic":
+			panic("boom")
+		}
+		<-release
+		return len(key), nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]int, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := l.Get("key")
+			if err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			results[i] = v
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected loader to run once, ran %d times", n)
+	}
+	for _, v := range results {
+		if v != 3 {
+			t.Errorf("expected 3, got %d", v)
+		}
+	}
+	if v, ok := l.Sharded.Get("key"); !ok || v != 3 {
+		t.Error("expected loaded value stored")
+	}
+
+	if _, err := l.Get("fail"); !errors.Is(err, errBoom) {
+		t.Errorf("expected loader error, got %v", err)
+	}
+	if l.Has("fail") {
+		t.Error("expected failed load not stored")
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		l.Get("panic")
+	}()
+	l.Set("panic", 7)
+	if v, err := l.Get("panic"); err != nil || v != 7 {
+		t.Errorf("expected key usable after a panic, got %d %v", v, err)
+	}
+}
+
+func TestSharded_ComputeE(t *testing.T) {
+	m := NewSharded[string, int]()
+	m.Set("balance", 10)
+	errInsufficient := errors.New("insufficient funds")
+
+	withdraw := func(amount int) (int, error) {
+		return m.ComputeE("balance", func(current int, exists bool) (int, bool, error) {
+			if current < amount {
+				return 0, false, errInsufficient
+			}
+			return current - amount, true, nil
+		})
+	}
+	if v, err := withdraw(4); err != nil || v != 6 {
+		t.Errorf("expected 6, got %d %v", v, err)
+	}
+	if _, err := withdraw(100); !errors.Is(err, errInsufficient) {
+		t.Errorf("expected error, got %v", err)
+	}
+	if v, ok := m.Get("balance"); !ok || v != 6 {
+		t.Errorf("failed ComputeE should leave the entry untouched, got %d %v", v, ok)
+	}
+
+	if _, err := m.ComputeE("missing", func(int, bool) (int, bool, error) {
+		return 0, false, errInsufficient
+	}); err == nil || m.Has("missing") {
+		t.Error("failed ComputeE should not create the key")
+	}
+}
+
+func TestSharded_RangeCtx(t *testing.T) {
+	m := NewSharded[int, int]()
+	for i := range 100 {
+		m.Set(i, i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	seen := 0
+	err := m.RangeCtx(ctx, func(int, int) bool {
+		seen++
+		if seen == 10 {
+			cancel()
+		}
+		return true
+	})
+	if !errors.Is(err, context.Canceled) || seen != 10 {
+		t.Errorf("expected cancellation after 10 entries, got %d %v", seen, err)
+	}
+
+	seen = 0
+	if err := m.RangeCtx(context.Background(), func(int, int) bool {
+		seen++
+		return true
+	}); err != nil || seen != 100 {
+		t.Errorf("expected full scan, got %d %v", seen, err)
+	}
+}
+
+func TestSharded_GetAndUpdate(t *testing.T) {
+	m := NewSharded[string, int]()
+	add := func(curr int, _ bool) int { return curr + 5 }
+
+	if old, cur, existed := m.GetAndUpdate("gauge", add); old != 0 || cur != 5 || existed {
+		t.Errorf("expected 0/5/false, got %d/%d/%v", old, cur, existed)
+	}
+	if old, cur, existed := m.GetAndUpdate("gauge", add); old != 5 || cur != 10 || !existed {
+		t.Errorf("expected 5/10/true, got %d/%d/%v", old, cur, existed)
+	}
+}
+
+func TestSharded_UpdateE(t *testing.T) {
+	m := NewSharded[string, int]()
+	errOverdraft := errors.New("overdraft")
+	withdraw := func(amount int) func(int, bool) (int, error) {
+		return func(balance int, _ bool) (int, error) {
+			if balance < amount {
+				return balance, errOverdraft
+			}
+			return balance - amount, nil
+		}
+	}
+	m.Set("acct", 100)
+
+	if v, err := m.UpdateE("acct", withdraw(30)); err != nil || v != 70 {
+		t.Errorf("expected 70/nil, got %d/%v", v, err)
+	}
+	if _, err := m.UpdateE("acct", withdraw(500)); !errors.Is(err, errOverdraft) {
+		t.Errorf("expected overdraft, got %v", err)
+	}
+	if v, _ := m.Get("acct"); v != 70 {
+		t.Errorf("expected balance untouched at 70, got %d", v)
+	}
+	if _, err := m.UpdateE("new", withdraw(1)); err == nil || m.Has("new") {
+		t.Error("expected failed update not to create the key")
+	}
+
+	old, cur, existed, err := m.GetAndUpdateE("acct", withdraw(20))
+	if err != nil || old != 70 || cur != 50 || !existed {
+		t.Errorf("expected 70/50/true/nil, got %d/%d/%v/%v", old, cur, existed, err)
+	}
+	old, cur, existed, err = m.GetAndUpdateE("acct", withdraw(60))
+	if !errors.Is(err, errOverdraft) || old != 50 || cur != 0 || !existed {
+		t.Errorf("expected 50/0/true/overdraft, got %d/%d/%v/%v", old, cur, existed, err)
+	}
+}
+
+func TestSharded_TransformValues(t *testing.T) {
+	m := NewSharded[int, int]()
+	for i := range 1000 {
+		m.Set(i, i)
+	}
+	double := func(_ int, v int) int { return v * 2 }
+
+	for _, parallelism := range []int{1, 8} {
+		if n := m.TransformValues(double, parallelism); n != 1000 {
+			t.Errorf("expected 1000 rewritten, got %d", n)
+		}
+	}
+	for i := range 1000 {
+		if v, _ := m.Get(i); v != i*4 {
+			t.Fatalf("expected %d at %d, got %d", i*4, i, v)
+		}
+	}
+}
+
+func TestSharded_TTL(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	m := NewShardedWithConfig[string, int](ShardedConfig{Clock: clk})
+
+	m.SetTTL("session", 1, time.Second)
+	m.Set("forever", 2)
+	if _, loaded := m.SetIfAbsentTTL("session", 9, time.Minute); !loaded {
+		t.Error("expected live entry to block SetIfAbsentTTL")
+	}
+	m.Update("session", func(v int, _ bool) int { return v + 1 })
+
+	clk.Advance(2 * time.Second)
+	if _, ok := m.Get("session"); ok {
+		t.Error("expected session expired")
+	}
+	if v, ok := m.Get("forever"); !ok || v != 2 {
+		t.Errorf("expected forever=2, got %d/%v", v, ok)
+	}
+
+	m.SetTTL("a", 1, time.Second)
+	m.SetTTL("b", 1, time.Second)
+	if actual, loaded := m.SetIfAbsentTTL("c", 3, time.Minute); loaded || actual != 3 {
+		t.Errorf("expected c stored, got %d/%v", actual, loaded)
+	}
+	clk.Advance(2 * time.Second)
+	if n := m.PurgeExpired(); n != 2 {
+		t.Errorf("expected 2 purged, got %d", n)
+	}
+	if m.Len() != 2 {
+		t.Errorf("expected 2 left, got %d", m.Len())
+	}
+	if _, loaded := m.SetIfAbsentTTL("c", 4, 0); !loaded {
+		t.Error("expected c still live")
+	}
+}
+
+func TestSharded_Hasher(t *testing.T) {
+	type key struct{ tenant, id string }
+	var calls atomic.Int64
+	m := NewShardedWithOptions(ShardedOptions[key, int]{
+		ShardedConfig: ShardedConfig{ShardCount: 64},
+		Hasher: func(k key, seed maphash.Seed) uint64 {
+			calls.Add(1)
+			return maphash.String(seed, k.tenant+"\x00"+k.id)
+		},
+	})
+
+	for i := range 100 {
+		m.Set(key{"acme", fmt.Sprint(i)}, i)
+	}
+	// Fresh string headers: only a value-based hasher finds these
+	for i := range 100 {
+		id := string([]byte(fmt.Sprint(i)))
+		if v, ok := m.Get(key{"acme", id}); !ok || v != i {
+			t.Fatalf("expected %d at %s, got %d/%v", i, id, v, ok)
+		}
+	}
+	if calls.Load() != 200 {
+		t.Errorf("expected 200 hasher calls, got %d", calls.Load())
+	}
+}
+
+func TestSharded_Seed(t *testing.T) {
+	// Two instances stand in for two process runs
+	a := NewShardedWithConfig[string, int](ShardedConfig{ShardCount: 16, Seed: 7})
+	b := NewShardedWithConfig[string, int](ShardedConfig{ShardCount: 16, Seed: 7})
+	for i := range 100 {
+		k := fmt.Sprint("key", i)
+		if a.ShardIndex(k) != b.ShardIndex(k) {
+			t.Fatalf("expected stable shard for %s, got %d and %d", k, a.ShardIndex(k), b.ShardIndex(k))
+		}
+	}
+	if st := a.Stats(); st.Size != 0 {
+		t.Errorf("expected empty map, got %d", st.Size)
+	}
+	for i := range 1000 {
+		a.Set(fmt.Sprint("key", i), i)
+	}
+	if st := a.Stats(); st.Imbalance > 1.5 {
+		t.Errorf("expected even spread, got imbalance %.2f", st.Imbalance)
+	}
+}
+
+func TestSharded_Batch(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	m := NewShardedWithConfig[int, int](ShardedConfig{ShardCount: 8, Clock: clk})
+	items := make(map[int]int, 500)
+	for i := range 500 {
+		items[i] = i * 10
+	}
+	m.SetMany(items)
+	m.SetManyTTL(map[int]int{1000: 1, 1001: 2}, time.Second)
+
+	keys := []int{1000, 1001, -1}
+	for i := range 500 {
+		keys = append(keys, i)
+	}
+	got := m.GetMany(keys)
+	if len(got) != 502 || got[499] != 4990 || got[1001] != 2 {
+		t.Errorf("unexpected GetMany result, %d entries", len(got))
+	}
+
+	clk.Advance(2 * time.Second)
+	if got := m.GetMany([]int{1000, 1001}); len(got) != 0 {
+		t.Errorf("expected expired keys omitted, got %v", got)
+	}
+	if n := m.DeleteMany(keys); n != 500 {
+		t.Errorf("expected 500 deleted, got %d", n)
+	}
+	if m.Len() != 0 {
+		t.Errorf("expected empty map, got %d", m.Len())
+	}
+}
+
+func TestSharded_RangeConsistent(t *testing.T) {
+	m := NewShardedWithConfig[int, int](ShardedConfig{ShardCount: 4})
+	for i := range 100 {
+		m.Set(i, i)
+	}
+
+	// fn runs on a copy: writing back must not deadlock or be observed
+	seen := 0
+	m.RangeConsistent(func(k, v int) bool {
+		if v != k {
+			t.Fatalf("expected %d at %d, got %d", k, k, v)
+		}
+		m.Set(k, -1)
+		seen++
+		return true
+	})
+	if seen != 100 {
+		t.Errorf("expected 100 items, got %d", seen)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				m.Set(i%100, i)
+			}
+		}
+	}()
+	for range 10 {
+		if snap := m.Snapshot(); len(snap) != 100 {
+			t.Errorf("expected 100 items in snapshot, got %d", len(snap))
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestSharded_LoadAndDeleteSwap(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	m := NewShardedWithConfig[string, int](ShardedConfig{Clock: clk})
+
+	if _, loaded := m.Swap("a", 1); loaded {
+		t.Error("expected no previous value")
+	}
+	if old, loaded := m.Swap("a", 2); !loaded || old != 1 {
+		t.Errorf("expected 1/true, got %d/%v", old, loaded)
+	}
+	if old, loaded := m.LoadAndDelete("a"); !loaded || old != 2 {
+		t.Errorf("expected 2/true, got %d/%v", old, loaded)
+	}
+	if _, loaded := m.LoadAndDelete("a"); loaded {
+		t.Error("expected key gone")
+	}
+
+	m.SetTTL("b", 1, time.Second)
+	clk.Advance(2 * time.Second)
+	if _, loaded := m.Swap("b", 2); loaded {
+		t.Error("expected expired value not reported")
+	}
+	m.SetTTL("c", 1, time.Second)
+	clk.Advance(2 * time.Second)
+	if _, loaded := m.LoadAndDelete("c"); loaded || m.Has("c") {
+		t.Error("expected expired key removed and not reported")
+	}
+}
+
+func TestSharded_CompareAndSwap(t *testing.T) {
+	m := NewSharded[string, int]()
+	m.Set("key", 1)
+	if m.CompareAndSwap("key", 2, 3) {
+		t.Error("expected no swap on mismatch")
+	}
+	if !m.CompareAndSwap("key", 1, 3) {
+		t.Error("expected swap on match")
+	}
+	if m.CompareAndSwap("missing", 0, 1) || m.Has("missing") {
+		t.Error("expected missing key left alone")
+	}
+	if !m.CompareAndSwapFunc("key", func(v int) bool { return v > 2 }, 4) {
+		t.Error("expected func swap")
+	}
+	if v, _ := m.Get("key"); v != 4 {
+		t.Errorf("expected 4, got %d", v)
+	}
+
+	// Non-comparable values fall back to DeepEqual
+	s := NewSharded[string, []int]()
+	s.Set("key", []int{1, 2})
+	if !s.CompareAndSwap("key", []int{1, 2}, []int{3}) {
+		t.Error("expected deep-equal swap")
+	}
+
+	// Custom equality
+	ci := NewShardedWithOptions(ShardedOptions[string, string]{Equal: strings.EqualFold})
+	ci.Set("key", "Hello")
+	if !ci.CompareAndSwap("key", "HELLO", "bye") {
+		t.Error("expected case-insensitive swap")
+	}
+}
+
+func TestSharded_Stats(t *testing.T) {
+	// Keys >= 100 all land on shard 0
+	m := NewShardedWithOptions(ShardedOptions[int, int]{
+		ShardedConfig: ShardedConfig{ShardCount: 4, EnableStats: true},
+		Hasher: func(k int, _ maphash.Seed) uint64 {
+			if k >= 100 {
+				return 0
+			}
+			return uint64(k)
+		},
+	})
+	for i := range 8 {
+		m.Set(i, i) // 2 per shard
+	}
+	for i := range 100 {
+		m.Set(100+i%8, i)
+	}
+
+	st := m.Stats()
+	if st.Size != 16 || st.Min != 2 || st.Max != 10 {
+		t.Errorf("expected size 16, min 2, max 10, got %d/%d/%d", st.Size, st.Min, st.Max)
+	}
+	if st.Mean != 4 || st.Imbalance != 2.5 {
+		t.Errorf("expected mean 4 and imbalance 2.5, got %v/%v", st.Mean, st.Imbalance)
+	}
+	if st.StdDev < 3.46 || st.StdDev > 3.47 {
+		t.Errorf("expected stddev ~3.464, got %v", st.StdDev)
+	}
+	if st.Ops[0] != 102 {
+		t.Errorf("expected 102 ops on shard 0, got %d", st.Ops[0])
+	}
+	if hot := st.HotShards(2); !slices.Equal(hot, []int{0}) {
+		t.Errorf("expected shard 0 hot, got %v", hot)
+	}
+
+	if NewSharded[int, int]().Stats().Ops != nil {
+		t.Error("expected no op counts without EnableStats")
+	}
+}
+
+func TestSharded_JSON(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	m := NewShardedWithConfig[int, string](ShardedConfig{ShardCount: 4, Clock: clk})
+	m.Set(1, "one")
+	m.SetTTL(2, "two", 10*time.Second)
+	m.SetTTL(3, "three", time.Second)
+	clk.Advance(2 * time.Second)
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	// Different shard count: entries are re-sharded on decode
+	restoredClk := NewFakeClock(time.Unix(100, 0))
+	restored := NewShardedWithConfig[int, string](ShardedConfig{ShardCount: 32, Clock: restoredClk})
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if restored.Len() != 2 {
+		t.Errorf("expected 2 entries restored, got %d", restored.Len())
+	}
+	restoredClk.Advance(7 * time.Second)
+	if !restored.Has(2) {
+		t.Error("expected remaining TTL of 8s kept")
+	}
+	restoredClk.Advance(2 * time.Second)
+	if restored.Has(2) || !restored.Has(1) {
+		t.Error("expected 2 expired and 1 kept")
+	}
+
+	// The format is shared with Concurrent
+	c := NewConcurrent[int, string]()
+	if err := json.Unmarshal(data, c); err != nil || !c.Has(1) || !c.Has(2) {
+		t.Errorf("expected Concurrent to read Sharded JSON, err %v", err)
+	}
+
+	var zero Sharded[int, string]
+	if err := json.Unmarshal(data, &zero); err != nil {
+		t.Fatalf("unmarshal into zero value failed: %v", err)
+	}
+	if !zero.Has(1) || !zero.Has(2) {
+		t.Error("expected zero Sharded usable after unmarshal")
+	}
+}
+
+func TestSharded_SaveLoad(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	m := NewShardedWithConfig[string, int](ShardedConfig{Clock: clk})
+	m.Set("a", 1)
+	m.SetTTL("b", 2, 10*time.Second)
+	m.SetTTL("c", 3, 5*time.Second)
+	m.SetTTL("gone", 4, time.Second)
+	clk.Advance(2 * time.Second)
+
+	var buf bytes.Buffer
+	if err := m.SaveTo(&buf); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	succClk := NewFakeClock(time.Unix(6, 0))
+	succ := NewShardedWithConfig[string, int](ShardedConfig{ShardCount: 2, Clock: succClk})
+	if err := succ.LoadFrom(&buf); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	keys := succ.Keys()
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("expected a and b restored, got %v", keys)
+	}
+	succClk.Set(time.Unix(11, 0))
+	if succ.Has("b") {
+		t.Error("expected absolute expiration kept")
+	}
+
+	if err := succ.LoadFrom(bytes.NewReader([]byte("junk"))); err == nil {
+		t.Error("expected error on invalid input")
+	}
+}
+
+func TestSharded_ShardView(t *testing.T) {
+	m := NewShardedWithConfig[int, int](ShardedConfig{ShardCount: 4})
+	if m.NumShards() != 4 {
+		t.Fatalf("expected 4 shards, got %d", m.NumShards())
+	}
+	for i := range 100 {
+		m.Shard(m.ShardIndex(i)).Set(i, i)
+	}
+
+	total := 0
+	for i := range m.NumShards() {
+		view := m.Shard(i)
+		n := 0
+		view.ForEach(func(k, v int) bool {
+			if m.ShardIndex(k) != i || k != v {
+				t.Errorf("unexpected %d=%d in shard %d", k, v, i)
+			}
+			n++
+			return true
+		})
+		if n != view.Len() {
+			t.Errorf("expected %d items in shard %d, got %d", view.Len(), i, n)
+		}
+		total += n
+	}
+	if total != 100 {
+		t.Errorf("expected 100 items, got %d", total)
+	}
+
+	own := m.Shard(m.ShardIndex(7))
+	if v, ok := own.Get(7); !ok || v != 7 {
+		t.Errorf("expected 7, got %d/%v", v, ok)
+	}
+	other := m.Shard((own.Index() + 1) % m.NumShards())
+	if _, ok := other.Get(7); ok {
+		t.Error("expected key missing from foreign shard")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on foreign key Set")
+		}
+	}()
+	other.Set(7, 0)
+}
+
+func TestSharded_MaxPerShardClear(t *testing.T) {
+	m := NewShardedWithOptions(ShardedOptions[int, int]{
+		ShardedConfig: ShardedConfig{MaxPerShard: 8},
+		Hasher:        func(int, maphash.Seed) uint64 { return 0 },
+	})
+	ring := m.shards[0].ring
+	fill := func(from int) {
+		for i := from; i < from+8; i++ {
+			m.Get(i - 8) // mark older keys used, so stale slots can't pose as victims
+			m.Set(i, i)
+		}
+		if m.Len() != 8 || len(ring.slots) != 8 {
+			t.Fatalf("expected 8 entries and slots without early evictions, got %d and %d", m.Len(), len(ring.slots))
+		}
+		for i := from; i < from+8; i++ {
+			if !m.Has(i) {
+				t.Fatalf("expected %d kept, got keys %v", i, m.Keys())
+			}
+		}
+	}
+
+	fill(0)
+	m.Clear()
+	if len(ring.slots) != 0 || ring.refs.Size() != 0 {
+		t.Errorf("expected Clear to empty the ring, got %d slots and %d refs", len(ring.slots), ring.refs.Size())
+	}
+	fill(100)
+
+	<-m.ClearAsync()
+	if len(ring.slots) != 0 || ring.refs.Size() != 0 {
+		t.Errorf("expected ClearAsync to empty the ring, got %d slots and %d refs", len(ring.slots), ring.refs.Size())
+	}
+	fill(200)
+
+	m.ClearIf(func(k, _ int) bool { return k%2 == 0 })
+	if len(ring.slots) != 4 || ring.refs.Size() != 4 {
+		t.Errorf("expected ClearIf to drop stale slots, got %d slots and %d refs", len(ring.slots), ring.refs.Size())
+	}
+	m.ClearIf(func(int, int) bool { return true })
+	fill(300)
+}
+
+func TestSharded_MaxPerShard(t *testing.T) {
+	// Single hot shard so the eviction order is predictable
+	m := NewShardedWithOptions(ShardedOptions[int, int]{
+		ShardedConfig: ShardedConfig{MaxPerShard: 4},
+		Hasher:        func(int, maphash.Seed) uint64 { return 0 },
+	})
+	for i := range 4 {
+		m.Set(i, i)
+	}
+	m.Get(0) // second chance for 0
+	m.Set(4, 4)
+	if m.Has(1) || !m.Has(0) || !m.Has(4) {
+		t.Errorf("expected 1 evicted and 0 kept, got keys %v", m.Keys())
+	}
+
+	// A deleted key's slot is reused without evicting
+	m.Delete(2)
+	m.Set(5, 5)
+	keys := m.Keys()
+	slices.Sort(keys)
+	if !slices.Equal(keys, []int{0, 3, 4, 5}) {
+		t.Errorf("expected [0 3 4 5], got %v", keys)
+	}
+	m.Update(3, func(v int, _ bool) int { return v + 1 }) // updates keep the slot
+	if v, _ := m.Get(3); v != 4 || m.Len() != 4 {
+		t.Errorf("expected 3=4 and 4 entries, got %d and %d", v, m.Len())
+	}
+
+	bounded := NewShardedWithConfig[int, int](ShardedConfig{ShardCount: 4, MaxPerShard: 50})
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				bounded.Set(g*1000+i, i)
+				bounded.Get(g*1000 + i/2)
+			}
+		}()
+	}
+	wg.Wait()
+	for i, n := range bounded.ShardStats() {
+		if n > 50 {
+			t.Errorf("expected at most 50 entries in shard %d, got %d", i, n)
+		}
+		if n := bounded.shards[i].ring.refs.Size(); n > 50 {
+			t.Errorf("expected at most 50 ref bits in shard %d, got %d", i, n)
+		}
+	}
+
+	// Unbounded maps carry no per-entry ref bit
+	type bare struct {
+		value      int
+		expiration int64
+	}
+	if size, want := unsafe.Sizeof(shardedEntry[int]{}), unsafe.Sizeof(bare{}); size != want {
+		t.Errorf("expected %d-byte entries, got %d", want, size)
+	}
+}
+
+func TestSharded_SizeHint(t *testing.T) {
+	m := NewShardedWithConfig[int, int](ShardedConfig{ShardCount: 4, SizeHint: 100_000})
+	for i := range m.shards {
+		if c := m.shards[i].m().Stats().Capacity; c < 25_000 {
+			t.Errorf("expected shard %d pre-sized for 25000, got capacity %d", i, c)
+		}
+	}
+	for i := range 100_000 {
+		m.Set(i, i)
+	}
+	for i := range m.shards {
+		if g := m.shards[i].m().Stats().TotalGrowths; g != 0 {
+			t.Errorf("expected no growth in shard %d, got %d", i, g)
+		}
+	}
+}
+
+func BenchmarkSharded_BulkLoad(b *testing.B) {
+	for _, hint := range []int{0, 1 << 20} {
+		b.Run(fmt.Sprintf("hint=%d", hint), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m := NewShardedWithConfig[int, int](ShardedConfig{SizeHint: hint})
+				for k := range 1 << 20 {
+					m.Set(k, k)
+				}
+			}
+		})
+	}
+}
+
+func TestSharded_Sorted(t *testing.T) {
+	m := NewSharded[string, int]()
+	for i, k := range []string{"c", "a", "d", "b"} {
+		m.Set(k, i)
+	}
+	less := func(a, b string) bool { return a < b }
+
+	if keys := m.KeysSorted(less); !slices.Equal(keys, []string{"a", "b", "c", "d"}) {
+		t.Errorf("expected sorted keys, got %v", keys)
+	}
+
+	o := m.ExportOrdered(less)
+	var got []string
+	o.Range(func(k string, v int) bool {
+		got = append(got, fmt.Sprintf("%s=%d", k, v))
+		return true
+	})
+	if !slices.Equal(got, []string{"a=1", "b=3", "c=0", "d=2"}) {
+		t.Errorf("expected ordered export, got %v", got)
+	}
+}
+
+// countingMetrics records ShardedMetrics calls per shard.
+type countingMetrics struct {
+	gets, misses, sets, deletes, expires [4]atomic.Int64
+}
+
+func (m *countingMetrics) Get(shard int)    { m.gets[shard].Add(1) }
+func (m *countingMetrics) Miss(shard int)   { m.misses[shard].Add(1) }
+func (m *countingMetrics) Set(shard int)    { m.sets[shard].Add(1) }
+func (m *countingMetrics) Delete(shard int) { m.deletes[shard].Add(1) }
+func (m *countingMetrics) Expire(shard int) { m.expires[shard].Add(1) }
+
+func TestSharded_Metrics(t *testing.T) {
+	metrics := &countingMetrics{}
+	clk := NewFakeClock(time.Unix(0, 0))
+	m := NewShardedWithOptions(ShardedOptions[int, int]{
+		ShardedConfig: ShardedConfig{ShardCount: 4, Clock: clk, Metrics: metrics},
+		Hasher:        func(k int, _ maphash.Seed) uint64 { return uint64(k) },
+	})
+
+	m.Set(1, 1)
+	m.SetIfAbsent(1, 2) // loaded, not a set
+	m.Get(1)
+	m.Get(5)                  // miss on shard 1
+	m.CompareAndSwap(1, 9, 3) // no match, not a set
+	m.Update(2, func(v int, _ bool) int { return v + 1 })
+	m.Delete(2)
+	m.Delete(2)                                                   // already gone, not a delete
+	m.Compute(3, func(int, bool) (int, bool) { return 0, false }) // nothing to delete
+	m.SetTTL(7, 7, time.Second)
+	clk.Advance(2 * time.Second)
+	m.ClearIf(func(int, int) bool { return false }) // expired, not a delete
+
+	want := map[string][4]int64{
+		"gets":    {0, 2, 0, 0},
+		"misses":  {0, 1, 0, 0},
+		"sets":    {0, 1, 1, 1},
+		"deletes": {0, 0, 1, 0},
+		"expires": {0, 0, 0, 1},
+	}
+	got := map[string]*[4]atomic.Int64{
+		"gets": &metrics.gets, "misses": &metrics.misses,
+		"sets": &metrics.sets, "deletes": &metrics.deletes,
+		"expires": &metrics.expires,
+	}
+	for name, counters := range got {
+		for i := range counters {
+			if n := counters[i].Load(); n != want[name][i] {
+				t.Errorf("expected %d %s on shard %d, got %d", want[name][i], name, i, n)
+			}
+		}
+	}
+}
+
+func TestSharded_Iterators(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	m := NewShardedWithConfig[string, int](ShardedConfig{Clock: clk})
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.SetTTL("c", 3, time.Second)
+	clk.Advance(2 * time.Second)
+
+	sum := 0
+	for k, v := range m.All() {
+		if k == "c" {
+			t.Error("expected expired entries skipped")
+		}
+		sum += v
+	}
+	if sum != 3 {
+		t.Errorf("expected sum 3, got %d", sum)
+	}
+	if keys := slices.Sorted(m.KeysSeq()); !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("unexpected keys %v", keys)
+	}
+	if values := slices.Sorted(m.ValuesSeq()); !slices.Equal(values, []int{1, 2}) {
+		t.Errorf("unexpected values %v", values)
+	}
+
+	n := 0
+	for shard := range m.Shards() {
+		for k := range shard.All() {
+			if m.ShardIndex(k) != shard.Index() {
+				t.Errorf("key %s yielded by wrong shard %d", k, shard.Index())
+			}
+			n++
+		}
+	}
+	if n != 2 {
+		t.Errorf("expected 2 items across shards, got %d", n)
+	}
+
+	for range m.All() {
+		break // early exit must not panic
+	}
+	for range m.Shards() {
+		break
+	}
+}
+
+func BenchmarkSharded_CompareAndSwap(b *testing.B) {
+	m := NewSharded[string, int]()
+	m.Set("key", 0)
+	for i := 0; i < b.N; i++ {
+		m.CompareAndSwap("key", i, i+1)
+	}
+}
+
+func BenchmarkSharded_GetMany(b *testing.B) {
+	m := NewSharded[int, int]()
+	keys := make([]int, 512)
+	for i := range keys {
+		keys[i] = i
+		m.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.GetMany(keys)
+	}
+}
+
 func BenchmarkSharded_Set(b *testing.B) {
 	s := NewSharded[string, int]()
 	for i := 0; i < b.N; i++ {
@@ -152,3 +1251,15 @@ func BenchmarkSharded_Get(b *testing.B) {
 		s.Get(fmt.Sprintf("key%d", i))
 	}
 }
+
+func BenchmarkSharded_SetParallel(b *testing.B) {
+	s := NewSharded[int, int]()
+	var seq atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(seq.Add(1)) << 10 // spread goroutines over the key space
+		for pb.Next() {
+			s.Set(i&0xfffff, i)
+			i++
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package mappo
+
+import "iter"
+
+// ShardIndex returns the index of the shard key maps to, in [0, NumShards()).
+// It is stable for the lifetime of the map, so callers can align their own
+// per-shard resources (locks, files, workers) with it.
+func (sm *Sharded[K, V]) ShardIndex(key K) int {
+	return sm.shardIndex(key)
+}
+
+// NumShards returns the number of shards, always a power of 2.
+func (sm *Sharded[K, V]) NumShards() int {
+	return len(sm.shards)
+}
+
+// Shard returns a view restricted to shard i. It panics if i is out of range.
+func (sm *Sharded[K, V]) Shard(i int) ShardView[K, V] {
+	_ = &sm.shards[i] // bounds check
+	return ShardView[K, V]{sm: sm, index: i}
+}
+
+// Shards returns an iterator over views of all shards, in index order.
+func (sm *Sharded[K, V]) Shards() iter.Seq[ShardView[K, V]] {
+	return func(yield func(ShardView[K, V]) bool) {
+		for i := range sm.shards {
+			if !yield(ShardView[K, V]{sm: sm, index: i}) {
+				return
+			}
+		}
+	}
+}
+
+// ShardView gives access to the items of a single shard.
+// Views are cheap values and stay valid for the lifetime of the map.
+type ShardView[K comparable, V any] struct {
+	sm    *Sharded[K, V]
+	index int
+}
+
+// Index returns the shard's index.
+func (v ShardView[K, V]) Index() int {
+	return v.index
+}
+
+// Get retrieves a value from this shard.
+// Keys that belong to another shard are never found.
+func (v ShardView[K, V]) Get(key K) (V, bool) {
+	return v.sm.load(v.shard(), key)
+}
+
+// Set stores a value with no expiration.
+// It panics if key belongs to another shard, see Sharded.ShardIndex.
+func (v ShardView[K, V]) Set(key K, val V) {
+	if v.sm.shardIndex(key) != v.index {
+		panic("mappo: key does not belong to this shard")
+	}
+	s := v.shard()
+	s.store(key, shardedEntry[V]{value: val})
+	v.sm.recordSet(s)
+}
+
+// ForEach iterates through the shard's items. Return false to stop iteration.
+// Expired items are skipped and deleted.
+func (v ShardView[K, V]) ForEach(fn func(K, V) bool) {
+	v.sm.rangeShard(&v.sm.shards[v.index], fn)
+}
+
+// All returns an iterator over the shard's non-expired items.
+func (v ShardView[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		v.ForEach(yield)
+	}
+}
+
+// Len returns the number of items in the shard, including expired ones not yet purged.
+func (v ShardView[K, V]) Len() int {
+	return v.sm.shards[v.index].m().Size()
+}
+
+// shard returns the underlying shard, counting the operation if stats are enabled.
+func (v ShardView[K, V]) shard() *shard[K, V] {
+	s := &v.sm.shards[v.index]
+	if v.sm.countOps {
+		s.ops.Add(1)
+	}
+	return s
+}
@@ -0,0 +1,144 @@
+package mappo
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SortedSet is a set kept in order by a comparison function, supporting
+// min/max, floor/ceiling, range and rank queries. It is backed by a sorted
+// slice: lookups are O(log n) and Add/Remove are O(n) moves, which stays fast
+// for sets up to a few hundred thousand elements.
+// Unlike the other containers its zero value is not ready to use, as it has
+// no order: create it with NewSortedSet or NewSortedSetFunc. Methods that
+// compare elements panic on a zero SortedSet.
+// SortedSet is not safe for concurrent use.
+type SortedSet[T any] struct {
+	items []T
+	cmp   func(a, b T) int
+}
+
+// NewSortedSet creates a SortedSet of naturally ordered elements.
+func NewSortedSet[T cmp.Ordered](elems ...T) *SortedSet[T] {
+	return NewSortedSetFunc(cmp.Compare[T], elems...)
+}
+
+// NewSortedSetFunc creates a SortedSet ordered by cmp, which returns a negative,
+// zero or positive int like cmp.Compare. Elements comparing equal are duplicates.
+func NewSortedSetFunc[T any](cmp func(a, b T) int, elems ...T) *SortedSet[T] {
+	if cmp == nil {
+		panic("mappo: nil comparison for NewSortedSetFunc")
+	}
+	items := slices.Clone(elems)
+	slices.SortFunc(items, cmp)
+	items = slices.CompactFunc(items, func(a, b T) bool { return cmp(a, b) == 0 })
+	return &SortedSet[T]{items: items, cmp: cmp}
+}
+
+// compare returns the set's order, panicking on a zero SortedSet.
+func (s *SortedSet[T]) compare() func(a, b T) int {
+	if s.cmp == nil {
+		panic("mappo: SortedSet used without NewSortedSet or NewSortedSetFunc")
+	}
+	return s.cmp
+}
+
+// search returns the position of elem and whether it is present.
+func (s *SortedSet[T]) search(elem T) (int, bool) {
+	return slices.BinarySearchFunc(s.items, elem, s.compare())
+}
+
+// Add inserts elem unless an equal element is present.
+func (s *SortedSet[T]) Add(elem T) {
+	if i, found := s.search(elem); !found {
+		s.items = slices.Insert(s.items, i, elem)
+	}
+}
+
+// Remove removes the element equal to elem, if any.
+func (s *SortedSet[T]) Remove(elem T) {
+	if i, found := s.search(elem); found {
+		s.items = slices.Delete(s.items, i, i+1)
+	}
+}
+
+// Has returns true if an element equal to elem exists.
+func (s *SortedSet[T]) Has(elem T) bool {
+	_, found := s.search(elem)
+	return found
+}
+
+// Len returns the number of elements.
+func (s *SortedSet[T]) Len() int {
+	return len(s.items)
+}
+
+// IsEmpty returns true if the set has no elements.
+func (s *SortedSet[T]) IsEmpty() bool {
+	return len(s.items) == 0
+}
+
+// Clear removes all elements.
+func (s *SortedSet[T]) Clear() {
+	clear(s.items)
+	s.items = s.items[:0]
+}
+
+// Elements returns all elements in ascending order.
+func (s *SortedSet[T]) Elements() []T {
+	return slices.Clone(s.items)
+}
+
+// Min returns the smallest element.
+func (s *SortedSet[T]) Min() (T, bool) {
+	return s.At(0)
+}
+
+// Max returns the largest element.
+func (s *SortedSet[T]) Max() (T, bool) {
+	return s.At(len(s.items) - 1)
+}
+
+// At returns the element of the given rank, counting from 0 for the smallest.
+func (s *SortedSet[T]) At(rank int) (T, bool) {
+	if rank < 0 || rank >= len(s.items) {
+		var zero T
+		return zero, false
+	}
+	return s.items[rank], true
+}
+
+// Rank returns how many elements are smaller than elem, which is the rank of
+// elem if present.
+func (s *SortedSet[T]) Rank(elem T) int {
+	i, _ := s.search(elem)
+	return i
+}
+
+// Floor returns the largest element less than or equal to x.
+func (s *SortedSet[T]) Floor(x T) (T, bool) {
+	i, found := s.search(x)
+	if found {
+		return s.items[i], true
+	}
+	return s.At(i - 1)
+}
+
+// Ceiling returns the smallest element greater than or equal to x.
+func (s *SortedSet[T]) Ceiling(x T) (T, bool) {
+	i, _ := s.search(x)
+	return s.At(i)
+}
+
+// RangeBetween returns the elements between a and b inclusive, in ascending order.
+func (s *SortedSet[T]) RangeBetween(a, b T) []T {
+	if s.compare()(a, b) > 0 {
+		return nil
+	}
+	lo, _ := s.search(a)
+	hi, found := s.search(b)
+	if found {
+		hi++
+	}
+	return slices.Clone(s.items[lo:hi])
+}
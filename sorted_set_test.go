@@ -0,0 +1,92 @@
+package mappo
+
+import (
+	"cmp"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSortedSet_Basic(t *testing.T) {
+	s := NewSortedSet(50, 10, 30, 10, 40)
+	if !reflect.DeepEqual(s.Elements(), []int{10, 30, 40, 50}) {
+		t.Fatalf("expected sorted unique elements, got %v", s.Elements())
+	}
+	s.Add(20)
+	s.Add(30)
+	s.Remove(50)
+	if !reflect.DeepEqual(s.Elements(), []int{10, 20, 30, 40}) {
+		t.Errorf("expected [10 20 30 40], got %v", s.Elements())
+	}
+	if lo, _ := s.Min(); lo != 10 {
+		t.Errorf("expected min 10, got %d", lo)
+	}
+	if hi, _ := s.Max(); hi != 40 {
+		t.Errorf("expected max 40, got %d", hi)
+	}
+	if v, ok := s.Floor(25); !ok || v != 20 {
+		t.Errorf("expected floor 20, got %d, %v", v, ok)
+	}
+	if v, ok := s.Floor(30); !ok || v != 30 {
+		t.Errorf("expected floor 30, got %d, %v", v, ok)
+	}
+	if _, ok := s.Floor(5); ok {
+		t.Error("expected no floor below min")
+	}
+	if v, ok := s.Ceiling(25); !ok || v != 30 {
+		t.Errorf("expected ceiling 30, got %d, %v", v, ok)
+	}
+	if _, ok := s.Ceiling(41); ok {
+		t.Error("expected no ceiling above max")
+	}
+	if got := s.RangeBetween(15, 30); !reflect.DeepEqual(got, []int{20, 30}) {
+		t.Errorf("expected [20 30], got %v", got)
+	}
+	if got := s.RangeBetween(30, 15); got != nil {
+		t.Errorf("expected nil for reversed range, got %v", got)
+	}
+	if s.Rank(30) != 2 || s.Rank(35) != 3 {
+		t.Errorf("expected ranks 2 and 3, got %d and %d", s.Rank(30), s.Rank(35))
+	}
+	if v, ok := s.At(1); !ok || v != 20 {
+		t.Errorf("expected 20 at rank 1, got %d, %v", v, ok)
+	}
+	s.Clear()
+	if _, ok := s.Min(); ok || !s.IsEmpty() {
+		t.Error("expected empty set")
+	}
+}
+
+func TestSortedSet_Leaderboard(t *testing.T) {
+	type entry struct {
+		score int
+		name  string
+	}
+	// Highest score first, ties broken by name
+	board := NewSortedSetFunc(func(a, b entry) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.name, b.name)
+	}, entry{90, "ann"}, entry{75, "bob"}, entry{90, "al"})
+
+	if top, _ := board.Min(); top.name != "al" {
+		t.Errorf("expected al first, got %v", top)
+	}
+	if rank := board.Rank(entry{75, "bob"}); rank != 2 {
+		t.Errorf("expected bob at rank 2, got %d", rank)
+	}
+}
+
+func TestSortedSet_ZeroValue(t *testing.T) {
+	var s SortedSet[int]
+	if s.Len() != 0 || !s.IsEmpty() {
+		t.Error("expected zero SortedSet to read as empty")
+	}
+	defer func() {
+		if msg, _ := recover().(string); !strings.Contains(msg, "NewSortedSet") {
+			t.Errorf("expected a panic pointing at the constructors, got %q", msg)
+		}
+	}()
+	s.Add(1)
+}
@@ -0,0 +1,393 @@
+package mappo
+
+import (
+	"database/sql/driver"
+	"io"
+	"iter"
+	"sync"
+)
+
+// SyncMapper is a Mapper guarded by a sync.RWMutex. It offers the Mapper
+// method set, plus Compute and GetOrSet, for shared state that doesn't need
+// the throughput of Concurrent or Sharded. The zero value is ready to use.
+//
+// Callbacks run with the lock held and must not call back into the SyncMapper.
+// Methods returning a Mapper or slice return copies that are safe to keep.
+type SyncMapper[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  Mapper[K, V]
+}
+
+// NewSyncMapper creates an empty SyncMapper.
+func NewSyncMapper[K comparable, V any]() *SyncMapper[K, V] {
+	return &SyncMapper[K, V]{m: NewMapper[K, V]()}
+}
+
+// NewSyncMapperFrom creates a SyncMapper that takes ownership of m.
+// The caller must not use m directly afterwards.
+func NewSyncMapperFrom[K comparable, V any](m Mapper[K, V]) *SyncMapper[K, V] {
+	if m == nil {
+		m = NewMapper[K, V]()
+	}
+	return &SyncMapper[K, V]{m: m}
+}
+
+// lazyInit allocates the map of a zero SyncMapper. Callers must hold mu.
+func (s *SyncMapper[K, V]) lazyInit() {
+	if s.m == nil {
+		s.m = NewMapper[K, V]()
+	}
+}
+
+// Get returns the value associated with the key, or the zero value.
+func (s *SyncMapper[K, V]) Get(key K) V {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Get(key)
+}
+
+// OK returns the value and a boolean indicating whether the key exists.
+func (s *SyncMapper[K, V]) OK(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.OK(key)
+}
+
+// Pop returns the value and deletes the key if it exists.
+func (s *SyncMapper[K, V]) Pop(key K) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Pop(key)
+}
+
+// SetDefault sets the value only if the key doesn't exist, returns the final value.
+func (s *SyncMapper[K, V]) SetDefault(key K, value V) V {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lazyInit()
+	return s.m.SetDefault(key, value)
+}
+
+// GetOrSet returns the existing value and true if the key exists,
+// otherwise stores value and returns it with false.
+func (s *SyncMapper[K, V]) GetOrSet(key K, value V) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.m[key]; ok {
+		return existing, true
+	}
+	s.lazyInit()
+	s.m[key] = value
+	return value, false
+}
+
+// Update atomically replaces the value with fn(current, exists) and returns it.
+func (s *SyncMapper[K, V]) Update(key K, fn func(V, bool) V) V {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lazyInit()
+	return s.m.Update(key, fn)
+}
+
+// Compute atomically reads and rewrites a key. fn receives the current value
+// and whether it exists; returning keep=false deletes the key.
+// API matches Concurrent.Compute
+func (s *SyncMapper[K, V]) Compute(key K, fn func(current V, exists bool) (newValue V, keep bool)) V {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, exists := s.m[key]
+	newValue, keep := fn(current, exists)
+	if !keep {
+		delete(s.m, key)
+		return newValue
+	}
+	s.lazyInit()
+	s.m[key] = newValue
+	return newValue
+}
+
+// Set sets the value for the specified key.
+func (s *SyncMapper[K, V]) Set(key K, value V) *SyncMapper[K, V] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lazyInit()
+	s.m[key] = value
+	return s
+}
+
+// Delete removes the specified key.
+func (s *SyncMapper[K, V]) Delete(key K) *SyncMapper[K, V] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, key)
+	return s
+}
+
+// DeleteMany removes the given keys and returns how many existed.
+func (s *SyncMapper[K, V]) DeleteMany(keys ...K) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.DeleteMany(keys...)
+}
+
+// DeleteIf removes every pair for which fn returns true and returns how many
+// were removed.
+func (s *SyncMapper[K, V]) DeleteIf(fn func(K, V) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.DeleteIf(fn)
+}
+
+// Has returns true if the key exists.
+func (s *SyncMapper[K, V]) Has(key K) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Has(key)
+}
+
+// Len returns the number of elements.
+func (s *SyncMapper[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.m)
+}
+
+// IsEmpty returns true if the map has no elements.
+func (s *SyncMapper[K, V]) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+// Keys returns a slice containing all keys.
+func (s *SyncMapper[K, V]) Keys() []K {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Keys()
+}
+
+// Clear removes all elements.
+func (s *SyncMapper[K, V]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Clear()
+}
+
+// Values returns a slice containing all values.
+func (s *SyncMapper[K, V]) Values() []V {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Values()
+}
+
+// Range iterates over each key-value pair under the read lock.
+func (s *SyncMapper[K, V]) Range(fn func(K, V)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.m.Range(fn)
+}
+
+// All returns an iterator over all key-value pairs.
+// The read lock is held until the loop finishes.
+func (s *SyncMapper[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		for k, v := range s.m {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over all keys.
+// The read lock is held until the loop finishes.
+func (s *SyncMapper[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range s.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over all values.
+// The read lock is held until the loop finishes.
+func (s *SyncMapper[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range s.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns a new Mapper containing only pairs that satisfy the predicate.
+func (s *SyncMapper[K, V]) Filter(fn func(K, V) bool) Mapper[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Filter(fn)
+}
+
+// Every reports whether fn holds for all pairs.
+func (s *SyncMapper[K, V]) Every(fn func(K, V) bool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Every(fn)
+}
+
+// Some reports whether fn holds for at least one pair.
+func (s *SyncMapper[K, V]) Some(fn func(K, V) bool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Some(fn)
+}
+
+// None reports whether fn holds for no pair.
+func (s *SyncMapper[K, V]) None(fn func(K, V) bool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.None(fn)
+}
+
+// MapValues returns a new Mapper with transformed values.
+func (s *SyncMapper[K, V]) MapValues(fn func(V) V) Mapper[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.MapValues(fn)
+}
+
+// Apply rewrites every value in place with fn, which also receives the key.
+func (s *SyncMapper[K, V]) Apply(fn func(K, V) V) *SyncMapper[K, V] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Apply(fn)
+	return s
+}
+
+// MapKeys returns a new Mapper with keys transformed by fn.
+// Panics if two keys map to the same value.
+func (s *SyncMapper[K, V]) MapKeys(fn func(K) K) Mapper[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.MapKeys(fn)
+}
+
+// Clone returns a shallow copy as a plain Mapper.
+func (s *SyncMapper[K, V]) Clone() Mapper[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Clone()
+}
+
+// Equal returns true if other holds identical key-value pairs.
+func (s *SyncMapper[K, V]) Equal(other Mapper[K, V], valueEq func(V, V) bool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Equal(other, valueEq)
+}
+
+// ToSlice converts to a slice of key-value pairs.
+func (s *SyncMapper[K, V]) ToSlice() []KeyValuePair[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.ToSlice()
+}
+
+// SortedKeys returns keys sorted by natural order (if possible).
+func (s *SyncMapper[K, V]) SortedKeys() []K {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.SortedKeys()
+}
+
+// SortedByValue returns all entries ordered by value.
+func (s *SyncMapper[K, V]) SortedByValue(less func(a, b V) bool) []KeyValuePair[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.SortedByValue(less)
+}
+
+// TopN returns the first n entries of SortedByValue.
+func (s *SyncMapper[K, V]) TopN(n int, less func(a, b V) bool) []KeyValuePair[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.TopN(n, less)
+}
+
+// MinBy returns the entry with the smallest value according to less.
+func (s *SyncMapper[K, V]) MinBy(less func(a, b V) bool) (KeyValuePair[K, V], bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.MinBy(less)
+}
+
+// MaxBy returns the entry with the largest value according to less.
+func (s *SyncMapper[K, V]) MaxBy(less func(a, b V) bool) (KeyValuePair[K, V], bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.MaxBy(less)
+}
+
+// Chunk splits the map into Mappers of at most size entries.
+func (s *SyncMapper[K, V]) Chunk(size int) []Mapper[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Chunk(size)
+}
+
+// Sample returns up to n entries chosen uniformly at random.
+func (s *SyncMapper[K, V]) Sample(n int) []KeyValuePair[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Sample(n)
+}
+
+// ToSharded copies the map into a new Sharded map built with cfg.
+func (s *SyncMapper[K, V]) ToSharded(cfg ShardedConfig) *Sharded[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.ToSharded(cfg)
+}
+
+// ToConcurrent copies the map into a new Concurrent map.
+func (s *SyncMapper[K, V]) ToConcurrent() *Concurrent[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.ToConcurrent()
+}
+
+// ToOrdered copies the map into a new Ordered map in the order given by less.
+func (s *SyncMapper[K, V]) ToOrdered(less func(a, b K) bool) *Ordered[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.ToOrdered(less)
+}
+
+// EncodeJSON writes the map to w as a JSON object. The read lock is held
+// while writing, so w should not block for long.
+func (s *SyncMapper[K, V]) EncodeJSON(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.EncodeJSON(w)
+}
+
+// Value implements driver.Valuer.
+func (s *SyncMapper[K, V]) Value() (driver.Value, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Value()
+}
+
+// Scan implements sql.Scanner, replacing the contents of the map.
+func (s *SyncMapper[K, V]) Scan(src any) error {
+	var m Mapper[K, V]
+	if err := m.Scan(src); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m = m
+	return nil
+}
@@ -0,0 +1,62 @@
+package mappo
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSyncMapper_Basic(t *testing.T) {
+	var s SyncMapper[string, int] // zero value is usable
+	s.Set("a", 1).Set("b", 2)
+	if s.Get("a") != 1 || s.Len() != 2 || !s.Has("b") {
+		t.Fatalf("expected two entries, got %v", s.Clone())
+	}
+	if v, loaded := s.GetOrSet("a", 10); !loaded || v != 1 {
+		t.Errorf("expected existing value 1, got %d, %v", v, loaded)
+	}
+	if v, loaded := s.GetOrSet("c", 3); loaded || v != 3 {
+		t.Errorf("expected stored value 3, got %d, %v", v, loaded)
+	}
+	s.Compute("c", func(int, bool) (int, bool) { return 0, false })
+	if s.Has("c") {
+		t.Error("expected Compute with keep=false to delete")
+	}
+	if v, ok := s.Pop("b"); !ok || v != 2 || s.Has("b") {
+		t.Errorf("expected Pop to remove b, got %d, %v", v, ok)
+	}
+	clone := s.Clone()
+	clone.Set("x", 9)
+	if s.Has("x") {
+		t.Error("expected Clone to be a copy")
+	}
+	for k := range s.KeysSeq() {
+		if k != "a" {
+			t.Errorf("unexpected key %q", k)
+		}
+	}
+}
+
+func TestSyncMapper_Concurrent(t *testing.T) {
+	s := NewSyncMapper[string, int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				s.Update("hits", func(v int, _ bool) int { return v + 1 })
+				s.Set(strconv.Itoa(g), i)
+				s.Get("hits")
+				s.Keys()
+			}
+		}(g)
+	}
+	wg.Wait()
+	if s.Get("hits") != 8000 {
+		t.Errorf("expected 8000 hits, got %d", s.Get("hits"))
+	}
+	if s.Len() != 9 {
+		t.Errorf("expected 9 keys, got %d", s.Len())
+	}
+}